@@ -1,14 +1,33 @@
 package zdpgo_json
 
-// ReadConfig 读取配置，支持同时读取多个
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/zhangdapeng520/zdpgo_json/jsoniter"
+)
+
+// ReadConfig 读取配置，支持同时读取多个。
+// 多个配置文件会先按顺序合并，后面文件中的值覆盖前面文件中的值，对象按键合并；
+// 然后对所有文件中都没有的键，按结构体字段的default标签填充默认值，
+// 例如 `default:"8080"`。文件中显式设置的零值不会被默认值覆盖。
 func ReadConfig(configObj interface{}, configFileList ...string) error {
+	var merged []byte
 	for _, configFile := range configFileList {
-		err := Load(configFile, configObj)
+		data, err := os.ReadFile(configFile)
 		if err != nil {
 			return err
 		}
+		merged, err = mergeConfig(merged, data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", configFile, err)
+		}
+	}
+	if merged == nil {
+		return FillDefaults(configObj)
 	}
-	return nil
+	return json.Unmarshal(merged, configObj)
 }
 
 // ReadDefaultConfig 读取默认配置。默认公共配置config/config.json，默认私密配置config/secret/.config.json
@@ -16,3 +35,46 @@ func ReadDefaultConfig(configObj interface{}) error {
 	err := ReadConfig(configObj, "config/config.json", "config/secret/.config.json")
 	return err
 }
+
+// FillDefaults 将结构体中值为零值的字段设置为其default标签的值，包括嵌套的结构体。
+// 字符串字段的默认值就是标签的文本，其他字段的默认值是json字面量，
+// 例如 `default:"[1,2]"`。Loads 和 Load 对json中缺失的键也会使用默认值。
+func FillDefaults(obj interface{}) error {
+	return jsoniter.FillDefaults(obj)
+}
+
+// mergeConfig merges the json of a config file into the json of the files
+// before it. The objects are merged key by key, the other values of src
+// replace the ones of dst.
+func mergeConfig(dst, src []byte) ([]byte, error) {
+	if !isJSONObject(src) || !isJSONObject(dst) {
+		var raw jsoniter.RawMessage
+		if err := json.Unmarshal(src, &raw); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	}
+	var dstObj, srcObj map[string]jsoniter.RawMessage
+	if err := json.Unmarshal(dst, &dstObj); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(src, &srcObj); err != nil {
+		return nil, err
+	}
+	for key, value := range srcObj {
+		if old, found := dstObj[key]; found {
+			merged, err := mergeConfig(old, value)
+			if err != nil {
+				return nil, err
+			}
+			value = merged
+		}
+		dstObj[key] = value
+	}
+	return json.Marshal(dstObj)
+}
+
+func isJSONObject(data []byte) bool {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	return len(data) > 0 && data[0] == '{'
+}
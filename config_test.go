@@ -0,0 +1,84 @@
+package zdpgo_json
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testConfig struct {
+	Debug bool   `default:"true"`
+	Port  int    `default:"8080"`
+	Host  string `default:"localhost"`
+	DB    struct {
+		Name string `default:"app"`
+		Pool int    `default:"10"`
+	}
+	Tags []string `default:"[\"a\"]"`
+}
+
+func writeConfigFiles(t *testing.T, contents ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var files []string
+	for i, content := range contents {
+		file := filepath.Join(dir, string(rune('a'+i))+".json")
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+func TestReadConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		check func(c testConfig) bool
+	}{
+		{"no files", nil, func(c testConfig) bool {
+			return c.Debug && c.Port == 8080 && c.Host == "localhost" && c.DB.Name == "app" &&
+				c.DB.Pool == 10 && len(c.Tags) == 1
+		}},
+		{"explicit zero is kept by a later file", []string{`{"Debug":false}`, `{"Port":1}`}, func(c testConfig) bool {
+			return !c.Debug && c.Port == 1 && c.Host == "localhost"
+		}},
+		{"later files win", []string{`{"Port":1,"Host":"a"}`, `{"Port":0}`}, func(c testConfig) bool {
+			return c.Port == 0 && c.Host == "a" && c.Debug
+		}},
+		{"nested objects are merged", []string{`{"DB":{"Name":""}}`, `{"DB":{"Pool":0}}`}, func(c testConfig) bool {
+			return c.DB.Name == "" && c.DB.Pool == 0
+		}},
+		{"nested defaults", []string{`{"DB":{"Pool":3}}`, `{}`}, func(c testConfig) bool {
+			return c.DB.Name == "app" && c.DB.Pool == 3
+		}},
+		{"arrays are replaced", []string{`{"Tags":["x","y"]}`, `{"Tags":[]}`}, func(c testConfig) bool {
+			return c.Tags != nil && len(c.Tags) == 0
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c testConfig
+			if err := ReadConfig(&c, writeConfigFiles(t, tt.files...)...); err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(c) {
+				t.Fatalf("%+v", c)
+			}
+		})
+	}
+}
+
+func TestReadConfigErrors(t *testing.T) {
+	var c testConfig
+	if err := ReadConfig(&c, filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+	if err := ReadConfig(&c, writeConfigFiles(t, `{"Port":1}`, `{"Port":`)...); err == nil {
+		t.Fatal("expected an error for an invalid file")
+	}
+	if err := ReadConfig(&c, writeConfigFiles(t, `{"Port":1} {}`)...); err == nil {
+		t.Fatal("expected an error for trailing data")
+	}
+}
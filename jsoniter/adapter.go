@@ -39,6 +39,20 @@ func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
 	return ConfigDefault.MarshalIndent(v, prefix, indent)
 }
 
+// MarshalView marshal only the struct fields in the view, such as "public".
+// A field tagged `view:"public,admin"` is only in these views, the fields
+// without a view tag are in every view.
+func MarshalView(v interface{}, view string) ([]byte, error) {
+	return ConfigDefault.(ViewAPI).MarshalView(v, view)
+}
+
+// MarshalFields marshal only the fields selected by the dotted paths, such
+// as "id", "name" and "owner.email". A '.' in a name is escaped as "\.", and
+// "items.id" selects the id of each element of items, like "items.#.id".
+func MarshalFields(v interface{}, paths []string) ([]byte, error) {
+	return ConfigDefault.(ViewAPI).MarshalFields(v, paths)
+}
+
 // MarshalToString convenient method to write as string instead of []byte
 func MarshalToString(v interface{}) (string, error) {
 	return ConfigDefault.MarshalToString(v)
@@ -64,6 +78,9 @@ type Decoder struct {
 func (adapter *Decoder) Decode(obj interface{}) error {
 	if adapter.iter.head == adapter.iter.tail && adapter.iter.reader != nil {
 		if !adapter.iter.loadMore() {
+			if err := adapter.iter.Error; err != nil && err != io.EOF {
+				return err
+			}
 			return io.EOF
 		}
 	}
@@ -103,6 +120,19 @@ func (adapter *Decoder) UseNumber() {
 	adapter.iter.cfg = cfg.frozeWithCacheReuse(adapter.iter.cfg.extraExtensions)
 }
 
+// UseOrderedMap causes the Decoder to unmarshal an object into an interface{}
+// as an *OrderedMap instead of as a map[string]interface{}.
+func (adapter *Decoder) UseOrderedMap() {
+	cfg := adapter.iter.cfg.configBeforeFrozen
+	cfg.UseOrderedMap = true
+	adapter.iter.cfg = cfg.frozeWithCacheReuse(adapter.iter.cfg.extraExtensions)
+}
+
+// OnDeprecated calls fn when a struct field tagged with deprecated is decoded
+func (adapter *Decoder) OnDeprecated(fn func(iter *Iterator, field DeprecatedField)) {
+	adapter.iter.OnDeprecated = fn
+}
+
 // DisallowUnknownFields causes the Decoder to return an error when the destination
 // is a struct and the input contains object keys which do not match any
 // non-ignored, exported fields in the destination.
@@ -124,12 +154,21 @@ type Encoder struct {
 
 // Encode encode interface{} as JSON to io.Writer
 func (adapter *Encoder) Encode(val interface{}) error {
+	start := len(adapter.stream.buf)
 	adapter.stream.WriteVal(val)
+	if adapter.stream.cfg.canonical {
+		adapter.stream.canonicalize(start)
+	}
 	adapter.stream.WriteRaw("\n")
 	adapter.stream.Flush()
 	return adapter.stream.Error
 }
 
+// SetView encode only the struct fields in the view, empty for all the fields
+func (adapter *Encoder) SetView(view string) {
+	adapter.stream.view = view
+}
+
 // SetIndent set the indention. Prefix is not supported
 func (adapter *Encoder) SetIndent(prefix, indent string) {
 	config := adapter.stream.cfg.configBeforeFrozen
@@ -172,6 +172,9 @@ func (iter *Iterator) readAny() Any {
 	case '-':
 		return iter.readNumberAny(false)
 	case 0:
+		if iter.Error != nil && iter.Error != io.EOF {
+			return &invalidAny{baseAny{}, iter.Error}
+		}
 		return &invalidAny{baseAny{}, errors.New("input is empty")}
 	default:
 		return iter.readNumberAny(true)
@@ -182,6 +185,9 @@ func (iter *Iterator) readNumberAny(positive bool) Any {
 	iter.startCapture(iter.head - 1)
 	iter.skipNumber()
 	lazyBuf := iter.stopCapture()
+	if iter.Error != nil && iter.Error != io.EOF {
+		return &invalidAny{baseAny{}, iter.Error}
+	}
 	return &numberLazyAny{baseAny{}, iter.cfg, lazyBuf, nil}
 }
 
@@ -189,6 +195,9 @@ func (iter *Iterator) readObjectAny() Any {
 	iter.startCapture(iter.head - 1)
 	iter.skipObject()
 	lazyBuf := iter.stopCapture()
+	if iter.Error != nil && iter.Error != io.EOF {
+		return &invalidAny{baseAny{}, iter.Error}
+	}
 	return &objectLazyAny{baseAny{}, iter.cfg, lazyBuf, nil}
 }
 
@@ -196,6 +205,9 @@ func (iter *Iterator) readArrayAny() Any {
 	iter.startCapture(iter.head - 1)
 	iter.skipArray()
 	lazyBuf := iter.stopCapture()
+	if iter.Error != nil && iter.Error != io.EOF {
+		return &invalidAny{baseAny{}, iter.Error}
+	}
 	return &arrayLazyAny{baseAny{}, iter.cfg, lazyBuf, nil}
 }
 
@@ -232,12 +244,18 @@ func locatePath(iter *Iterator, path []interface{}) Any {
 		switch pathKey := pathKeyObj.(type) {
 		case string:
 			valueBytes := locateObjectField(iter, pathKey)
+			if iter.Error != nil && iter.Error != io.EOF {
+				return &invalidAny{baseAny{}, iter.Error}
+			}
 			if valueBytes == nil {
 				return newInvalidAny(path[i:])
 			}
 			iter.ResetBytes(valueBytes)
 		case int:
 			valueBytes := locateArrayElement(iter, pathKey)
+			if iter.Error != nil && iter.Error != io.EOF {
+				return &invalidAny{baseAny{}, iter.Error}
+			}
 			if valueBytes == nil {
 				return newInvalidAny(path[i:])
 			}
@@ -0,0 +1,579 @@
+package jsoniter
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// MutableAny is an Any that can be edited with Set, Delete and Append.
+// Only the objects and arrays on an edited path are expanded, every other
+// subtree keeps its raw bytes and is copied verbatim by WriteTo, so
+// "parse, tweak one field, forward" stays cheap.
+type MutableAny struct {
+	baseAny
+	cfg *frozenConfig
+	raw []byte // the original bytes, nil once edited or when built from a value
+	val Any    // the unexpanded value, parsed from raw on demand
+	// kind is ObjectValue or ArrayValue once the node is expanded into keys
+	// and elems, InvalidValue before.
+	kind  ValueType
+	keys  []string
+	elems []*MutableAny
+	err   error
+}
+
+// Mutable turn an Any into MutableAny, lazy values keep their raw bytes
+func Mutable(any Any) *MutableAny {
+	if m, ok := any.(*MutableAny); ok {
+		return m
+	}
+	m := &MutableAny{cfg: ConfigDefault.(*frozenConfig), val: any}
+	switch lazy := any.(type) {
+	case *objectLazyAny:
+		m.cfg, m.raw = lazy.cfg, lazy.buf
+	case *arrayLazyAny:
+		m.cfg, m.raw = lazy.cfg, lazy.buf
+	case *numberLazyAny:
+		m.cfg, m.raw = lazy.cfg, lazy.buf
+	}
+	return m
+}
+
+// ParseMutable parse data lazily into a MutableAny
+func ParseMutable(data []byte) *MutableAny {
+	return ConfigDefault.(*frozenConfig).ParseMutable(data)
+}
+
+// ParseMutable parse data lazily into a MutableAny using the config
+func (cfg *frozenConfig) ParseMutable(data []byte) *MutableAny {
+	return &MutableAny{cfg: cfg, raw: data}
+}
+
+// NewMutableObject returns an empty object to be filled with Put
+func NewMutableObject() *MutableAny {
+	return &MutableAny{cfg: ConfigDefault.(*frozenConfig), kind: ObjectValue, keys: []string{}}
+}
+
+// NewMutableArray returns an empty array to be filled with Add
+func NewMutableArray() *MutableAny {
+	return &MutableAny{cfg: ConfigDefault.(*frozenConfig), kind: ArrayValue}
+}
+
+// Put sets the object field and returns the object for chaining,
+// the error of a failed put is kept in LastError
+func (any *MutableAny) Put(key string, val interface{}) *MutableAny {
+	if err := any.Set(val, key); err != nil && any.err == nil {
+		any.err = err
+	}
+	return any
+}
+
+// Add appends to the array and returns the array for chaining,
+// the error of a failed add is kept in LastError
+func (any *MutableAny) Add(val interface{}) *MutableAny {
+	if err := any.Append(val); err != nil && any.err == nil {
+		any.err = err
+	}
+	return any
+}
+
+// Set sets the value at the path, path elements are string keys or int
+// indexes. Missing objects along the path are created for string keys,
+// an empty path replaces the whole value. A MutableAny value is inserted
+// as it is, it must not contain the node it is set into.
+func (any *MutableAny) Set(val interface{}, path ...interface{}) error {
+	if len(path) == 0 {
+		if val == any {
+			return nil
+		}
+		if contains(val, any) {
+			return fmt.Errorf("Set %v: value contains itself", path)
+		}
+		any.replace(val)
+		return nil
+	}
+	node, err := any.walk(path[:len(path)-1], true)
+	if err != nil {
+		return err
+	}
+	if contains(val, node) {
+		return fmt.Errorf("Set %v: value contains itself", path)
+	}
+	if err := node.expand(); err != nil {
+		return fmt.Errorf("Set %v: %v", path, err)
+	}
+	switch key := path[len(path)-1].(type) {
+	case string:
+		if node.kind != ObjectValue {
+			return fmt.Errorf("Set %v: field %q of non-object value", path, key)
+		}
+		if i := node.fieldIndex(key); i >= 0 {
+			node.elems[i].replace(val)
+			return nil
+		}
+		node.keys = append(node.keys, key)
+		node.elems = append(node.elems, node.child(val))
+		return nil
+	case int:
+		if node.kind != ArrayValue {
+			return fmt.Errorf("Set %v: index %d of non-array value", path, key)
+		}
+		if key < 0 || key >= len(node.elems) {
+			return fmt.Errorf("Set %v: index %d out of range", path, key)
+		}
+		node.elems[key].replace(val)
+		return nil
+	}
+	return fmt.Errorf("Set %v: invalid path element %v", path, path[len(path)-1])
+}
+
+// Delete removes the field or element at the path
+func (any *MutableAny) Delete(path ...interface{}) error {
+	if len(path) == 0 {
+		return fmt.Errorf("Delete: empty path")
+	}
+	node, err := any.walk(path[:len(path)-1], false)
+	if err != nil {
+		return err
+	}
+	if err := node.expand(); err != nil {
+		return fmt.Errorf("Delete %v: %v", path, err)
+	}
+	switch key := path[len(path)-1].(type) {
+	case string:
+		i := -1
+		if node.kind == ObjectValue {
+			i = node.fieldIndex(key)
+		}
+		if i < 0 {
+			return fmt.Errorf("Delete %v: field %q not found", path, key)
+		}
+		node.keys = append(node.keys[:i], node.keys[i+1:]...)
+		node.elems = append(node.elems[:i], node.elems[i+1:]...)
+		return nil
+	case int:
+		if node.kind != ArrayValue || key < 0 || key >= len(node.elems) {
+			return fmt.Errorf("Delete %v: index %d not found", path, key)
+		}
+		node.elems = append(node.elems[:key], node.elems[key+1:]...)
+		return nil
+	}
+	return fmt.Errorf("Delete %v: invalid path element %v", path, path[len(path)-1])
+}
+
+// Append appends the value to the array at the path
+func (any *MutableAny) Append(val interface{}, path ...interface{}) error {
+	node, err := any.walk(path, false)
+	if err != nil {
+		return err
+	}
+	if err := node.expand(); err != nil || node.kind != ArrayValue {
+		return fmt.Errorf("Append %v: not an array", path)
+	}
+	if contains(val, node) {
+		return fmt.Errorf("Append %v: value contains itself", path)
+	}
+	node.elems = append(node.elems, node.child(val))
+	return nil
+}
+
+// walk expands the nodes along the path and returns the last one
+func (any *MutableAny) walk(path []interface{}, create bool) (*MutableAny, error) {
+	node := any
+	for i, elem := range path {
+		if err := node.expand(); err != nil {
+			return nil, fmt.Errorf("%v: %v", path[:i+1], err)
+		}
+		switch key := elem.(type) {
+		case string:
+			if node.kind != ObjectValue {
+				return nil, fmt.Errorf("%v: field %q of non-object value", path[:i+1], key)
+			}
+			j := node.fieldIndex(key)
+			if j < 0 {
+				if !create {
+					return nil, fmt.Errorf("%v: field %q not found", path[:i+1], key)
+				}
+				child := NewMutableObject()
+				child.cfg = node.cfg
+				node.keys = append(node.keys, key)
+				node.elems = append(node.elems, child)
+				j = len(node.elems) - 1
+			}
+			node = node.elems[j]
+		case int:
+			if node.kind != ArrayValue || key < 0 || key >= len(node.elems) {
+				return nil, fmt.Errorf("%v: index %d not found", path[:i+1], key)
+			}
+			node = node.elems[key]
+		default:
+			return nil, fmt.Errorf("%v: invalid path element %v", path[:i+1], elem)
+		}
+	}
+	return node, nil
+}
+
+// replace makes the node hold a copy of the value in place, so that the
+// parents keep pointing to it
+func (any *MutableAny) replace(val interface{}) {
+	*any = *any.child(val).clone()
+}
+
+// clone copies the expanded nodes, the raw bytes and unexpanded values are
+// shared as they are never written
+func (any *MutableAny) clone() *MutableAny {
+	c := *any
+	if any.kind != InvalidValue {
+		c.keys = append([]string(nil), any.keys...)
+		c.elems = make([]*MutableAny, len(any.elems))
+		for i, elem := range any.elems {
+			c.elems[i] = elem.clone()
+		}
+	}
+	return &c
+}
+
+// contains tells if the value is a MutableAny that holds the node, setting
+// it into the node would make a cycle
+func contains(val interface{}, node *MutableAny) bool {
+	m, ok := val.(*MutableAny)
+	if !ok {
+		return false
+	}
+	if m == node {
+		return true
+	}
+	for _, elem := range m.elems {
+		if contains(elem, node) {
+			return true
+		}
+	}
+	return false
+}
+
+// child turns a value into a node of the same config
+func (any *MutableAny) child(val interface{}) *MutableAny {
+	if m, ok := val.(*MutableAny); ok {
+		return m
+	}
+	m := Mutable(Wrap(val))
+	if m.raw == nil {
+		m.cfg = any.cfg
+	}
+	return m
+}
+
+func (any *MutableAny) fieldIndex(key string) int {
+	for i, k := range any.keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// value returns the unexpanded value, parsing the raw bytes on first use
+func (any *MutableAny) value() Any {
+	if any.val == nil {
+		if any.raw == nil {
+			any.val = &nilAny{}
+		} else {
+			any.val = any.cfg.Get(any.raw)
+		}
+	}
+	return any.val
+}
+
+// expand splits an object or array into child nodes, the children of raw
+// bytes keep their own raw bytes
+func (any *MutableAny) expand() error {
+	if any.kind != InvalidValue {
+		return nil
+	}
+	kind := any.value().ValueType()
+	switch {
+	case kind == ObjectValue && any.raw != nil:
+		keys := []string{}
+		var elems []*MutableAny
+		iter := any.cfg.BorrowIterator(any.raw)
+		defer any.cfg.ReturnIterator(iter)
+		iter.ReadMapCB(func(iter *Iterator, field string) bool {
+			keys = append(keys, field)
+			elems = append(elems, any.cfg.ParseMutable(iter.SkipAndReturnBytes()))
+			return true
+		})
+		if iter.Error != nil {
+			return iter.Error
+		}
+		any.keys, any.elems = keys, elems
+	case kind == ArrayValue && any.raw != nil:
+		var elems []*MutableAny
+		iter := any.cfg.BorrowIterator(any.raw)
+		defer any.cfg.ReturnIterator(iter)
+		iter.ReadArrayCB(func(iter *Iterator) bool {
+			elems = append(elems, any.cfg.ParseMutable(iter.SkipAndReturnBytes()))
+			return true
+		})
+		if iter.Error != nil {
+			return iter.Error
+		}
+		any.elems = elems
+	case kind == ObjectValue:
+		any.keys = any.val.Keys()
+		for _, key := range any.keys {
+			any.elems = append(any.elems, any.child(any.val.Get(key)))
+		}
+	case kind == ArrayValue:
+		for i := 0; i < any.val.Size(); i++ {
+			any.elems = append(any.elems, any.child(any.val.Get(i)))
+		}
+	default:
+		return fmt.Errorf("cannot edit %v value", valueTypeName(kind))
+	}
+	any.kind, any.raw, any.val = kind, nil, nil
+	return nil
+}
+
+func valueTypeName(kind ValueType) string {
+	switch kind {
+	case StringValue:
+		return "string"
+	case NumberValue:
+		return "number"
+	case NilValue:
+		return "null"
+	case BoolValue:
+		return "bool"
+	case ArrayValue:
+		return "array"
+	case ObjectValue:
+		return "object"
+	}
+	return "invalid"
+}
+
+func (any *MutableAny) ValueType() ValueType {
+	if any.kind != InvalidValue {
+		return any.kind
+	}
+	return any.value().ValueType()
+}
+
+func (any *MutableAny) MustBeValid() Any {
+	if any.kind == InvalidValue {
+		any.value().MustBeValid()
+	}
+	return any
+}
+
+func (any *MutableAny) LastError() error {
+	if any.err != nil || any.kind != InvalidValue {
+		return any.err
+	}
+	return any.value().LastError()
+}
+
+func (any *MutableAny) ToBool() bool {
+	switch any.kind {
+	case ObjectValue:
+		return true
+	case ArrayValue:
+		return len(any.elems) > 0
+	}
+	return any.value().ToBool()
+}
+
+func (any *MutableAny) ToInt() int {
+	if any.kind != InvalidValue {
+		return 0
+	}
+	return any.value().ToInt()
+}
+
+func (any *MutableAny) ToInt32() int32 {
+	if any.kind != InvalidValue {
+		return 0
+	}
+	return any.value().ToInt32()
+}
+
+func (any *MutableAny) ToInt64() int64 {
+	if any.kind != InvalidValue {
+		return 0
+	}
+	return any.value().ToInt64()
+}
+
+func (any *MutableAny) ToUint() uint {
+	if any.kind != InvalidValue {
+		return 0
+	}
+	return any.value().ToUint()
+}
+
+func (any *MutableAny) ToUint32() uint32 {
+	if any.kind != InvalidValue {
+		return 0
+	}
+	return any.value().ToUint32()
+}
+
+func (any *MutableAny) ToUint64() uint64 {
+	if any.kind != InvalidValue {
+		return 0
+	}
+	return any.value().ToUint64()
+}
+
+func (any *MutableAny) ToFloat32() float32 {
+	if any.kind != InvalidValue {
+		return 0
+	}
+	return any.value().ToFloat32()
+}
+
+func (any *MutableAny) ToFloat64() float64 {
+	if any.kind != InvalidValue {
+		return 0
+	}
+	return any.value().ToFloat64()
+}
+
+func (any *MutableAny) ToString() string {
+	if any.kind == InvalidValue {
+		return any.value().ToString()
+	}
+	buf := any.bytes()
+	return *(*string)(unsafe.Pointer(&buf))
+}
+
+func (any *MutableAny) ToVal(obj interface{}) {
+	iter := any.cfg.BorrowIterator(any.bytes())
+	defer any.cfg.ReturnIterator(iter)
+	iter.ReadVal(obj)
+}
+
+// bytes serializes the node, untouched subtrees are copied verbatim
+func (any *MutableAny) bytes() []byte {
+	if any.kind == InvalidValue && any.raw != nil {
+		return any.raw
+	}
+	stream := any.cfg.BorrowStream(nil)
+	defer any.cfg.ReturnStream(stream)
+	any.WriteTo(stream)
+	return append([]byte(nil), stream.Buffer()...)
+}
+
+func (any *MutableAny) Get(path ...interface{}) Any {
+	if len(path) == 0 {
+		return any
+	}
+	if any.kind == InvalidValue {
+		return any.value().Get(path...)
+	}
+	switch firstPath := path[0].(type) {
+	case string:
+		if any.kind == ObjectValue {
+			if i := any.fieldIndex(firstPath); i >= 0 {
+				return any.elems[i].Get(path[1:]...)
+			}
+		}
+	case int:
+		if any.kind == ArrayValue && firstPath >= 0 && firstPath < len(any.elems) {
+			return any.elems[firstPath].Get(path[1:]...)
+		}
+	case int32:
+		if '*' != firstPath {
+			break
+		}
+		if any.kind == ObjectValue {
+			mappedAll := map[string]Any{}
+			for i, key := range any.keys {
+				mapped := any.elems[i].Get(path[1:]...)
+				if mapped.ValueType() != InvalidValue {
+					mappedAll[key] = mapped
+				}
+			}
+			return wrapMap(mappedAll)
+		}
+		mappedAll := make([]Any, 0, len(any.elems))
+		for _, elem := range any.elems {
+			mapped := elem.Get(path[1:]...)
+			if mapped.ValueType() != InvalidValue {
+				mappedAll = append(mappedAll, mapped)
+			}
+		}
+		return wrapArray(mappedAll)
+	}
+	return newInvalidAny(path)
+}
+
+func (any *MutableAny) Size() int {
+	if any.kind == InvalidValue {
+		return any.value().Size()
+	}
+	return len(any.elems)
+}
+
+func (any *MutableAny) Keys() []string {
+	if any.kind == InvalidValue {
+		return any.value().Keys()
+	}
+	return append([]string{}, any.keys...)
+}
+
+func (any *MutableAny) GetInterface() interface{} {
+	switch any.kind {
+	case ObjectValue:
+		obj := make(map[string]interface{}, len(any.keys))
+		for i, key := range any.keys {
+			obj[key] = any.elems[i].GetInterface()
+		}
+		return obj
+	case ArrayValue:
+		arr := make([]interface{}, len(any.elems))
+		for i, elem := range any.elems {
+			arr[i] = elem.GetInterface()
+		}
+		return arr
+	}
+	return any.value().GetInterface()
+}
+
+func (any *MutableAny) WriteTo(stream *Stream) {
+	switch any.kind {
+	case ObjectValue:
+		if len(any.keys) == 0 {
+			stream.WriteEmptyObject()
+			return
+		}
+		stream.WriteObjectStart()
+		for i, key := range any.keys {
+			if i > 0 {
+				stream.WriteMore()
+			}
+			stream.WriteObjectField(key)
+			any.elems[i].WriteTo(stream)
+		}
+		stream.WriteObjectEnd()
+	case ArrayValue:
+		if len(any.elems) == 0 {
+			stream.WriteEmptyArray()
+			return
+		}
+		stream.WriteArrayStart()
+		for i, elem := range any.elems {
+			if i > 0 {
+				stream.WriteMore()
+			}
+			elem.WriteTo(stream)
+		}
+		stream.WriteArrayEnd()
+	default:
+		if any.raw != nil {
+			stream.Write(any.raw)
+			return
+		}
+		any.value().WriteTo(stream)
+	}
+}
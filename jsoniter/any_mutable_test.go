@@ -0,0 +1,141 @@
+package jsoniter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMutableAnyEdits(t *testing.T) {
+	const input = `{"a": 1,  "b" : {"c": [1, 2 ,3], "d": "x"}, "e": [ {"f": true} ]}`
+	tests := []struct {
+		name   string
+		edit   func(m *MutableAny) error
+		output string
+	}{
+		// the untouched values keep their bytes, with the spaces before them
+		{"untouched", func(m *MutableAny) error { return nil }, input},
+		{"set field", func(m *MutableAny) error { return m.Set("y", "b", "d") },
+			`{"a": 1,"b":{"c": [1, 2 ,3],"d":"y"},"e": [ {"f": true} ]}`},
+		{"set new field", func(m *MutableAny) error { return m.Set(2, "b", "z") },
+			`{"a": 1,"b":{"c": [1, 2 ,3],"d": "x","z":2},"e": [ {"f": true} ]}`},
+		{"set creates objects", func(m *MutableAny) error { return m.Set(nil, "g", "h") },
+			`{"a": 1,"b": {"c": [1, 2 ,3], "d": "x"},"e": [ {"f": true} ],"g":{"h":null}}`},
+		{"set index", func(m *MutableAny) error { return m.Set(false, "e", 0, "f") },
+			`{"a": 1,"b": {"c": [1, 2 ,3], "d": "x"},"e":[{"f":false}]}`},
+		{"delete field", func(m *MutableAny) error { return m.Delete("b", "c") },
+			`{"a": 1,"b":{"d": "x"},"e": [ {"f": true} ]}`},
+		{"delete index", func(m *MutableAny) error { return m.Delete("b", "c", 1) },
+			`{"a": 1,"b":{"c":[1,3],"d": "x"},"e": [ {"f": true} ]}`},
+		{"append", func(m *MutableAny) error { return m.Append(map[string]int{"n": 1}, "e") },
+			`{"a": 1,"b": {"c": [1, 2 ,3], "d": "x"},"e":[{"f": true},{"n":1}]}`},
+		{"replace root", func(m *MutableAny) error { return m.Set([]int{1}) }, `[1]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := ParseMutable([]byte(input))
+			if err := tt.edit(m); err != nil {
+				t.Fatal(err)
+			}
+			if output := m.ToString(); output != tt.output {
+				t.Fatalf("expected %s, got %s", tt.output, output)
+			}
+			var decoded interface{}
+			if err := UnmarshalFromString(m.ToString(), &decoded); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestMutableAnyErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(m *MutableAny) error
+		err  string
+	}{
+		{"set into number", func(m *MutableAny) error { return m.Set(1, "a", "x") }, "cannot edit number"},
+		{"set field of array", func(m *MutableAny) error { return m.Set(1, "b", "x") }, "non-object"},
+		{"index out of range", func(m *MutableAny) error { return m.Set(1, "b", 5) }, "out of range"},
+		{"delete missing", func(m *MutableAny) error { return m.Delete("z") }, "not found"},
+		{"delete empty path", func(m *MutableAny) error { return m.Delete() }, "empty path"},
+		{"append to object", func(m *MutableAny) error { return m.Append(1) }, "not an array"},
+		{"invalid path element", func(m *MutableAny) error { return m.Set(1, 1.5) }, "invalid path"},
+		{"set self", func(m *MutableAny) error { return m.Set(m, "self") }, "contains itself"},
+		{"set parent", func(m *MutableAny) error {
+			m.Get("b")
+			if err := m.Set(1, "b", 0); err != nil {
+				return err
+			}
+			return m.Set(m, "b", 0)
+		}, "contains itself"},
+		{"append self", func(m *MutableAny) error { return m.Append(m, "b") }, "contains itself"},
+		{"replace with holder", func(m *MutableAny) error {
+			holder := NewMutableArray().Add(m)
+			return m.Set(holder)
+		}, "contains itself"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := ParseMutable([]byte(`{"a": 1, "b": [0]}`))
+			err := tt.edit(m)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+			// a rejected edit leaves a value that can be written
+			m.ToString()
+		})
+	}
+	m := ParseMutable([]byte(`[1]`))
+	if err := m.Set(m); err != nil || m.ToString() != `[1]` {
+		t.Fatalf("setting the value to itself: %v %s", err, m.ToString())
+	}
+}
+
+func TestMutableAnyReplaceCopies(t *testing.T) {
+	src := NewMutableArray().Add(1)
+	m := ParseMutable([]byte(`{"a": null, "b": null}`))
+	if err := m.Set(src, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Set(src, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Append(2, "a"); err != nil {
+		t.Fatal(err)
+	}
+	src.Add(3)
+	if output := m.ToString(); output != `{"a":[1,2],"b":[1]}` {
+		t.Fatalf("replaced values share their elements: %s", output)
+	}
+}
+
+func TestMutableAnyBuilders(t *testing.T) {
+	obj := NewMutableObject().
+		Put("name", "x").
+		Put("tags", NewMutableArray().Add("a").Add(1)).
+		Put("raw", Get([]byte(`{"k" : [ 1 ]}`)))
+	if err := obj.LastError(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"name":"x","tags":["a",1],"raw":{"k" : [ 1 ]}}`
+	if output := obj.ToString(); output != expected {
+		t.Fatalf("expected %s, got %s", expected, output)
+	}
+	if obj.Get("tags", 1).ToInt() != 1 || obj.Get("raw", "k", 0).ToInt() != 1 {
+		t.Fatal("Get through the edited nodes")
+	}
+	if keys := obj.Keys(); strings.Join(keys, ",") != "name,tags,raw" {
+		t.Fatalf("keys %v", keys)
+	}
+	var decoded struct {
+		Name string
+		Tags []interface{}
+	}
+	obj.ToVal(&decoded)
+	if decoded.Name != "x" || len(decoded.Tags) != 2 {
+		t.Fatalf("ToVal %+v", decoded)
+	}
+	if NewMutableArray().Add(1).Put("k", 1).LastError() == nil {
+		t.Fatal("Put on an array should keep an error")
+	}
+}
@@ -0,0 +1,31 @@
+package jsoniter
+
+import (
+	"crypto/sha256"
+
+	"github.com/zhangdapeng520/zdpgo_json/pretty"
+)
+
+// CanonicalSHA256 marshal v in the canonical form of RFC 8785 and returns
+// the SHA-256 digest of it
+func CanonicalSHA256(v interface{}) ([32]byte, error) {
+	data, err := ConfigCanonical.Marshal(v)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
+
+// canonicalize rewrites what was written to the stream since start in the
+// canonical form, the float and string formats of the encoders are replaced
+func (stream *Stream) canonicalize(start int) {
+	if stream.Error != nil {
+		return
+	}
+	data, err := pretty.Canonical(stream.buf[start:])
+	if err != nil {
+		stream.Error = err
+		return
+	}
+	stream.buf = append(stream.buf[:start], data...)
+}
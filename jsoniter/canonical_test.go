@@ -0,0 +1,79 @@
+package jsoniter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	type inner struct {
+		Z string  `json:"z"`
+		A float64 `json:"a"`
+	}
+	tests := []struct {
+		name   string
+		input  interface{}
+		output string
+	}{
+		{"sorted struct fields", struct {
+			B int   `json:"b"`
+			A inner `json:"a"`
+		}{1, inner{"x", 2}}, `{"a":{"a":2,"z":"x"},"b":1}`},
+		{"sorted map keys", map[string]int{"b": 1, "a": 2, "€": 3, "\r": 4},
+			`{"\r":4,"a":2,"b":1,"€":3}`},
+		{"keys by UTF-16 code units", map[string]int{"\ufb33": 1, "\U0001F600": 2},
+			"{\"\U0001F600\":2,\"\ufb33\":1}"},
+		{"numbers", []float64{1.0, 0.1, 100, 1e21, 1e-7, -0.0, 333333333.3333333, 1.5e300},
+			`[1,0.1,100,1e+21,1e-7,0,333333333.3333333,1.5e+300]`},
+		{"integers", []int64{0, -1, 9007199254740991}, `[0,-1,9007199254740991]`},
+		{"strings", []string{"<>&", " ", "\x1f", "é", "\"\\/", "\b\f\n\r\t"},
+			`["<>&","` + " " + `","\u001f","é","\"\\/","\b\f\n\r\t"]`},
+		{"raw message", map[string]RawMessage{"b": RawMessage(` { "d" : 1.50, "c" : [ ] } `), "a": RawMessage(`"é"`)},
+			`{"a":"é","b":{"c":[],"d":1.5}}`},
+		{"empty", map[string]interface{}{"a": []int{}, "b": map[string]int{}, "c": nil},
+			`{"a":[],"b":{},"c":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := ConfigCanonical.MarshalToString(tt.input)
+			if err != nil || output != tt.output {
+				t.Fatalf("expected %s, got %s %v", tt.output, output, err)
+			}
+			data, err := ConfigCanonical.Marshal(tt.input)
+			if err != nil || string(data) != tt.output {
+				t.Fatalf("Marshal %s %v", data, err)
+			}
+			var buf bytes.Buffer
+			if err := ConfigCanonical.NewEncoder(&buf).Encode(tt.input); err != nil || buf.String() != tt.output+"\n" {
+				t.Fatalf("Encoder %q %v", buf.String(), err)
+			}
+			indented, err := ConfigCanonical.MarshalIndent(tt.input, "", "  ")
+			if err != nil || string(indented) != tt.output {
+				t.Fatalf("MarshalIndent should not indent: %s %v", indented, err)
+			}
+		})
+	}
+}
+
+func TestCanonicalErrors(t *testing.T) {
+	for _, v := range []interface{}{math.NaN(), math.Inf(1), []float64{math.Inf(-1)}} {
+		if output, err := ConfigCanonical.MarshalToString(v); err == nil {
+			t.Fatalf("%v: expected an error, got %s", v, output)
+		}
+	}
+}
+
+func TestCanonicalSHA256(t *testing.T) {
+	sum, err := CanonicalSHA256(map[string]interface{}{"b": 2, "a": []float64{1.0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != sha256.Sum256([]byte(`{"a":[1],"b":2}`)) {
+		t.Fatalf("digest of the canonical form %x", sum)
+	}
+	if _, err := CanonicalSHA256(math.NaN()); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -25,6 +25,30 @@ type Config struct {
 	ValidateJsonRawMessage        bool
 	ObjectFieldMustBeSimpleString bool
 	CaseSensitive                 bool
+	UseOrderedMap                 bool
+	// The limits for untrusted input, 0 means no limit except for MaxDepth,
+	// which defaults to 10000. A LimitError is reported when one is exceeded.
+	MaxDepth         int // the nesting of arrays and objects
+	MaxBytes         int // the size of the input, or the bytes read from a reader
+	MaxStringLength  int // the bytes of a string after unescaping, as query counts them
+	MaxNumberLength  int // the characters of a number, without the sign
+	MaxArrayElements int // the elements of an array
+	MaxObjectKeys    int // the keys of an object
+	DuplicateKeys    DuplicateKeyPolicy
+	// StrictIJSON rejects what I-JSON (RFC 7493) forbids: invalid UTF-8,
+	// lone surrogate escapes, numbers out of the double range and, unless
+	// DuplicateKeys says otherwise, duplicate keys
+	StrictIJSON bool
+	// ReplaceInvalidUTF8 replaces invalid UTF-8 and lone surrogates in
+	// strings with U+FFFD, instead of passing or rejecting them
+	ReplaceInvalidUTF8 bool
+	// Canonical marshals in the canonical form of RFC 8785, the JSON
+	// Canonicalization Scheme, IndentionStep, EscapeHTML and SortMapKeys
+	// do not apply then
+	Canonical bool
+	// DefaultsOnNull applies the default tag of a field when its key is
+	// null, not only when it is missing
+	DefaultsOnNull bool
 }
 
 // API the public interface of this package.
@@ -46,6 +70,15 @@ type API interface {
 	EncoderOf(typ reflect2.Type) ValEncoder
 }
 
+// ViewAPI the API with MarshalView and MarshalFields, they are kept out of
+// API so that the other implementations of API are not broken. The API of
+// Froze implements it.
+type ViewAPI interface {
+	API
+	MarshalView(v interface{}, view string) ([]byte, error)
+	MarshalFields(v interface{}, paths []string) ([]byte, error)
+}
+
 // ConfigDefault the default API
 var ConfigDefault = Config{
 	EscapeHTML: true,
@@ -65,6 +98,11 @@ var ConfigFastest = Config{
 	ObjectFieldMustBeSimpleString: true, // do not unescape object field
 }.Froze()
 
+// ConfigCanonical marshals in the canonical form of RFC 8785, for signing and hashing
+var ConfigCanonical = Config{
+	Canonical: true,
+}.Froze()
+
 type frozenConfig struct {
 	configBeforeFrozen            Config
 	sortMapKeys                   bool
@@ -80,6 +118,19 @@ type frozenConfig struct {
 	streamPool                    *sync.Pool
 	iteratorPool                  *sync.Pool
 	caseSensitive                 bool
+	maxDepth                      int
+	maxBytes                      int
+	maxStringLength               int
+	maxNumberLength               int
+	maxArrayElements              int
+	maxObjectKeys                 int
+	duplicateKeys                 DuplicateKeyPolicy
+	strictIJSON                   bool
+	replaceInvalidUTF8            bool
+	validateUTF8                  bool
+	canonical                     bool
+	defaultsOnNull                bool
+	checkedSkip                   bool
 }
 
 func (cfg *frozenConfig) initCache() {
@@ -134,6 +185,29 @@ func (cfg Config) Froze() API {
 		onlyTaggedField:               cfg.OnlyTaggedField,
 		disallowUnknownFields:         cfg.DisallowUnknownFields,
 		caseSensitive:                 cfg.CaseSensitive,
+		maxDepth:                      cfg.MaxDepth,
+		maxBytes:                      cfg.MaxBytes,
+		maxStringLength:               cfg.MaxStringLength,
+		maxNumberLength:               cfg.MaxNumberLength,
+		maxArrayElements:              cfg.MaxArrayElements,
+		maxObjectKeys:                 cfg.MaxObjectKeys,
+		duplicateKeys:                 cfg.DuplicateKeys,
+		strictIJSON:                   cfg.StrictIJSON,
+		replaceInvalidUTF8:            cfg.ReplaceInvalidUTF8,
+		validateUTF8:                  cfg.StrictIJSON || cfg.ReplaceInvalidUTF8,
+		canonical:                     cfg.Canonical,
+		defaultsOnNull:                cfg.DefaultsOnNull,
+		checkedSkip: cfg.MaxStringLength > 0 || cfg.MaxNumberLength > 0 ||
+			cfg.MaxArrayElements > 0 || cfg.MaxObjectKeys > 0 || cfg.StrictIJSON,
+	}
+	if api.maxDepth <= 0 {
+		api.maxDepth = maxDepth
+	}
+	if cfg.StrictIJSON && cfg.DuplicateKeys == DuplicateKeysAllowLast {
+		api.duplicateKeys = DuplicateKeysError
+	}
+	if api.duplicateKeys == DuplicateKeysError {
+		api.checkedSkip = true
 	}
 	api.streamPool = &sync.Pool{
 		New: func() interface{} {
@@ -287,6 +361,9 @@ func (cfg *frozenConfig) MarshalToString(v interface{}) (string, error) {
 	stream := cfg.BorrowStream(nil)
 	defer cfg.ReturnStream(stream)
 	stream.WriteVal(v)
+	if cfg.canonical {
+		stream.canonicalize(0)
+	}
 	if stream.Error != nil {
 		return "", stream.Error
 	}
@@ -296,7 +373,36 @@ func (cfg *frozenConfig) MarshalToString(v interface{}) (string, error) {
 func (cfg *frozenConfig) Marshal(v interface{}) ([]byte, error) {
 	stream := cfg.BorrowStream(nil)
 	defer cfg.ReturnStream(stream)
+	return stream.marshal(v)
+}
+
+// MarshalView marshal only the struct fields in the view, the fields without
+// a view tag are in every view
+func (cfg *frozenConfig) MarshalView(v interface{}, view string) ([]byte, error) {
+	stream := cfg.BorrowStream(nil)
+	defer cfg.ReturnStream(stream)
+	stream.view = view
+	return stream.marshal(v)
+}
+
+// MarshalFields marshal only the fields selected by the dotted paths, such as
+// "id" and "owner.email", the other struct fields and map keys are skipped.
+// Without paths the whole value is marshaled.
+func (cfg *frozenConfig) MarshalFields(v interface{}, paths []string) ([]byte, error) {
+	stream := cfg.BorrowStream(nil)
+	defer cfg.ReturnStream(stream)
+	if len(paths) > 0 {
+		stream.fields = newFieldSelection(paths)
+	}
+	return stream.marshal(v)
+}
+
+// marshal write v and returns a copy of the buffer
+func (stream *Stream) marshal(v interface{}) ([]byte, error) {
 	stream.WriteVal(v)
+	if stream.cfg.canonical {
+		stream.canonicalize(0)
+	}
 	if stream.Error != nil {
 		return nil, stream.Error
 	}
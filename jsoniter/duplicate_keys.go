@@ -0,0 +1,139 @@
+package jsoniter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DuplicateKeyPolicy decides what happens to the repeated keys of an object
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeysAllowLast keeps the value of the last key, the default
+	DuplicateKeysAllowLast DuplicateKeyPolicy = iota
+	// DuplicateKeysAllowFirst keeps the value of the first key and skips the others
+	DuplicateKeysAllowFirst
+	// DuplicateKeysError reports a DuplicateKeyError
+	DuplicateKeysError
+)
+
+func (policy DuplicateKeyPolicy) String() string {
+	switch policy {
+	case DuplicateKeysAllowLast:
+		return "allow-last"
+	case DuplicateKeysAllowFirst:
+		return "allow-first"
+	case DuplicateKeysError:
+		return "error"
+	}
+	return "DuplicateKeyPolicy(" + strconv.Itoa(int(policy)) + ")"
+}
+
+// ErrDuplicateKey is wrapped by DuplicateKeyError, check it with errors.Is
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// DuplicateKeyError is reported for a repeated key with DuplicateKeysError
+type DuplicateKeyError struct {
+	Path string // the dotted path of the key, array indexes are numbers
+	msg  string
+}
+
+func (err *DuplicateKeyError) Error() string {
+	return err.msg
+}
+
+func (err *DuplicateKeyError) Unwrap() error {
+	return ErrDuplicateKey
+}
+
+// keySet remembers the keys of an object while duplicates are checked,
+// it is nil with DuplicateKeysAllowLast
+type keySet map[interface{}]struct{}
+
+func (iter *Iterator) newKeySet() keySet {
+	if iter.cfg.duplicateKeys == DuplicateKeysAllowLast {
+		return nil
+	}
+	return keySet{}
+}
+
+// acceptKey tells if the value of the key should be read, a false means
+// that it is a duplicate that must be skipped. The id identifies the key,
+// name is used in the path of the error.
+func (iter *Iterator) acceptKey(seen keySet, id interface{}, name string) bool {
+	if seen == nil {
+		return true
+	}
+	if _, found := seen[id]; !found {
+		seen[id] = struct{}{}
+		return true
+	}
+	if iter.cfg.duplicateKeys == DuplicateKeysError {
+		iter.reportDuplicateKey(name)
+	}
+	return false
+}
+
+func (iter *Iterator) reportDuplicateKey(name string) {
+	if iter.Error != nil && iter.Error != io.EOF {
+		return
+	}
+	path := strings.Join(append(iter.keyPath[:len(iter.keyPath):len(iter.keyPath)], name), ".")
+	iter.Error = nil
+	iter.ReportError("DuplicateKey", fmt.Sprintf("%v: %s", ErrDuplicateKey, path))
+	iter.Error = &DuplicateKeyError{Path: path, msg: iter.Error.Error()}
+}
+
+// pushKey and popKey track the path of the value being read while
+// duplicates are checked, so that the error can tell where the key is
+func (iter *Iterator) pushKey(seen keySet, name string) {
+	if seen != nil {
+		iter.keyPath = append(iter.keyPath, name)
+	}
+}
+
+func (iter *Iterator) popKey(seen keySet) {
+	if seen != nil && len(iter.keyPath) > 0 {
+		iter.keyPath = iter.keyPath[:len(iter.keyPath)-1]
+	}
+}
+
+// pushIndex and popIndex track the array elements in the path
+func (iter *Iterator) pushIndex(index int) {
+	if iter.cfg.duplicateKeys != DuplicateKeysAllowLast {
+		iter.keyPath = append(iter.keyPath, strconv.Itoa(index))
+	}
+}
+
+func (iter *Iterator) popIndex() {
+	if iter.cfg.duplicateKeys != DuplicateKeysAllowLast && len(iter.keyPath) > 0 {
+		iter.keyPath = iter.keyPath[:len(iter.keyPath)-1]
+	}
+}
+
+// callField calls the callback of ReadObjectCB or ReadMapCB, unless the
+// field is a duplicate that must be skipped
+func (iter *Iterator) callField(seen keySet, field string, callback func(*Iterator, string) bool) bool {
+	if !iter.acceptKey(seen, field, field) {
+		if iter.cfg.duplicateKeys == DuplicateKeysError {
+			return false
+		}
+		iter.Skip()
+		return true
+	}
+	iter.pushKey(seen, field)
+	ok := callback(iter, field)
+	iter.popKey(seen)
+	return ok
+}
+
+// callElement calls the callback of ReadArrayCB
+func (iter *Iterator) callElement(index int, callback func(*Iterator) bool) bool {
+	iter.pushIndex(index)
+	ok := callback(iter)
+	iter.popIndex()
+	return ok
+}
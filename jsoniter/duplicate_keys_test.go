@@ -0,0 +1,114 @@
+package jsoniter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+	type inner struct {
+		B int `json:"b"`
+	}
+	type target struct {
+		A int     `json:"a"`
+		N inner   `json:"n"`
+		L []inner `json:"l"`
+	}
+	tests := []struct {
+		name  string
+		input string
+		last  string // the output of each policy, or the path of the error
+		first string
+		path  string
+	}{
+		{"no duplicates", `{"a":1,"n":{"b":2}}`,
+			`{"a":1,"n":{"b":2}}`, `{"a":1,"n":{"b":2}}`, ""},
+		{"top level", `{"a":1,"a":2}`,
+			`{"a":2}`, `{"a":1}`, "a"},
+		{"nested object", `{"n":{"b":1,"b":2}}`,
+			`{"n":{"b":2}}`, `{"n":{"b":1}}`, "n.b"},
+		{"in array", `{"l":[{"b":1},{"b":2,"b":3}]}`,
+			`{"l":[{"b":1},{"b":3}]}`, `{"l":[{"b":1},{"b":2}]}`, "l.1.b"},
+		{"duplicate object", `{"n":{"b":1},"n":{"b":2}}`,
+			`{"n":{"b":2}}`, `{"n":{"b":1}}`, "n"},
+	}
+	policies := []struct {
+		policy DuplicateKeyPolicy
+		output func(tt int) string
+	}{
+		{DuplicateKeysAllowLast, func(i int) string { return tests[i].last }},
+		{DuplicateKeysAllowFirst, func(i int) string { return tests[i].first }},
+		{DuplicateKeysError, func(i int) string {
+			if tests[i].path != "" {
+				return ""
+			}
+			return tests[i].last
+		}},
+	}
+	for _, p := range policies {
+		api := Config{DuplicateKeys: p.policy, SortMapKeys: true}.Froze()
+		for i, tt := range tests {
+			t.Run(p.policy.String()+"/"+tt.name, func(t *testing.T) {
+				expected := p.output(i)
+				check := func(what string, v interface{}, err error) {
+					t.Helper()
+					if expected == "" {
+						var dupErr *DuplicateKeyError
+						if !errors.Is(err, ErrDuplicateKey) || !errors.As(err, &dupErr) || dupErr.Path != tt.path {
+							t.Fatalf("%s: expected a duplicate key at %s, got %v", what, tt.path, err)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("%s: %v", what, err)
+					}
+					output, _ := api.MarshalToString(v)
+					if what == "struct" {
+						// the struct writes all of its fields, compare them through a map
+						var m map[string]interface{}
+						Unmarshal([]byte(output), &m)
+						var e map[string]interface{}
+						Unmarshal([]byte(expected), &e)
+						for k := range m {
+							if _, found := e[k]; !found {
+								delete(m, k)
+							}
+						}
+						output, _ = api.MarshalToString(m)
+					}
+					if output != expected {
+						t.Fatalf("%s: expected %s, got %s", what, expected, output)
+					}
+				}
+				var v interface{}
+				err := api.UnmarshalFromString(tt.input, &v)
+				check("interface", v, err)
+				var m map[string]interface{}
+				err = api.UnmarshalFromString(tt.input, &m)
+				check("map", m, err)
+				var s target
+				err = api.UnmarshalFromString(tt.input, &s)
+				check("struct", s, err)
+				var o OrderedMap
+				err = api.UnmarshalFromString(tt.input, &o)
+				check("ordered map", &o, err)
+				if valid := api.Valid([]byte(tt.input)); valid != (expected != "") {
+					t.Fatalf("Valid is %v", valid)
+				}
+			})
+		}
+	}
+}
+
+func TestDuplicateKeysAny(t *testing.T) {
+	api := Config{DuplicateKeys: DuplicateKeysError}.Froze()
+	if err := api.Get([]byte(`{"a":{"b":1,"b":2}}`)).LastError(); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected a duplicate key, got %v", err)
+	}
+	if api.Get([]byte(`{"a":1,"b":2}`), "b").ToInt() != 2 {
+		t.Fatal("Get")
+	}
+	if (DuplicateKeyPolicy(7)).String() != "DuplicateKeyPolicy(7)" {
+		t.Fatal("String")
+	}
+}
@@ -0,0 +1,149 @@
+package extra
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/zhangdapeng520/zdpgo_json/jsoniter"
+)
+
+// RedactedValue is written instead of the fields tagged with redact
+const RedactedValue = "[REDACTED]"
+
+// SupportRedaction hide sensitive fields when encoding, for all the APIs.
+// A field tagged `json:"password,redact"` is encoded as "[REDACTED]", a field
+// tagged `mask:"last4"` is encoded as a string that only shows its last 4
+// characters. Register a RedactionExtension to an API to redact only there.
+func SupportRedaction() {
+	jsoniter.RegisterExtension(&RedactionExtension{})
+}
+
+// RedactionExtension redact the fields tagged with redact or mask when
+// encoding. Decoding is not changed. The masks are:
+//
+//	lastN   keep the last N characters, such as last4
+//	firstN  keep the first N characters
+//	all     replace every character
+//
+// An unknown mask redacts the whole value. Values too short to keep N
+// characters are masked entirely, nil values are still null.
+type RedactionExtension struct {
+	jsoniter.DummyExtension
+	Replacement string // written for redact, defaults to RedactedValue
+	MaskChar    rune   // defaults to '*'
+}
+
+// UpdateStructDescriptor wrap the encoders of the tagged fields
+func (extension *RedactionExtension) UpdateStructDescriptor(structDescriptor *jsoniter.StructDescriptor) {
+	for _, binding := range structDescriptor.Fields {
+		tag := binding.Field.Tag()
+		redact := false
+		for _, tagPart := range strings.Split(tag.Get("json"), ",")[1:] {
+			if tagPart == "redact" {
+				redact = true
+			}
+		}
+		if mask, hasMask := tag.Lookup("mask"); hasMask && !redact {
+			keep, fromEnd, ok := parseMask(mask)
+			if ok {
+				binding.Encoder = &maskEncoder{binding.Encoder, extension.maskChar(), keep, fromEnd}
+				continue
+			}
+			redact = true
+		}
+		if redact {
+			binding.Encoder = &redactEncoder{binding.Encoder, extension.replacement()}
+		}
+	}
+}
+
+func (extension *RedactionExtension) replacement() string {
+	if extension.Replacement == "" {
+		return RedactedValue
+	}
+	return extension.Replacement
+}
+
+func (extension *RedactionExtension) maskChar() rune {
+	if extension.MaskChar == 0 {
+		return '*'
+	}
+	return extension.MaskChar
+}
+
+// parseMask returns how many characters the mask keeps, and from which end
+func parseMask(mask string) (keep int, fromEnd bool, ok bool) {
+	switch {
+	case mask == "all":
+		return 0, false, true
+	case strings.HasPrefix(mask, "last"):
+		keep, err := strconv.Atoi(mask[len("last"):])
+		return keep, true, err == nil && keep >= 0
+	case strings.HasPrefix(mask, "first"):
+		keep, err := strconv.Atoi(mask[len("first"):])
+		return keep, false, err == nil && keep >= 0
+	}
+	return 0, false, false
+}
+
+type redactEncoder struct {
+	encoder     jsoniter.ValEncoder
+	replacement string
+}
+
+func (encoder *redactEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	stream.WriteString(encoder.replacement)
+}
+
+func (encoder *redactEncoder) IsEmpty(ptr unsafe.Pointer) bool {
+	return encoder.encoder.IsEmpty(ptr)
+}
+
+type maskEncoder struct {
+	encoder  jsoniter.ValEncoder
+	maskChar rune
+	keep     int
+	fromEnd  bool
+}
+
+// Encode write the value with its own encoder, then replace what was written
+// with the masked text of it
+func (encoder *maskEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	start := len(stream.Buffer())
+	encoder.encoder.Encode(ptr, stream)
+	buf := stream.Buffer()
+	text := string(buf[start:])
+	if text == "null" {
+		return
+	}
+	if strings.HasPrefix(text, `"`) {
+		text = jsoniter.ParseString(jsoniter.ConfigDefault, text).ReadString()
+	}
+	stream.SetBuffer(buf[:start])
+	stream.WriteString(encoder.mask(text))
+}
+
+func (encoder *maskEncoder) mask(text string) string {
+	n := utf8.RuneCountInString(text)
+	keep := encoder.keep
+	if keep >= n {
+		keep = 0
+	}
+	masked := make([]rune, 0, n)
+	i := 0
+	for _, c := range text {
+		if (encoder.fromEnd && i >= n-keep) || (!encoder.fromEnd && i < keep) {
+			masked = append(masked, c)
+		} else {
+			masked = append(masked, encoder.maskChar)
+		}
+		i++
+	}
+	return string(masked)
+}
+
+func (encoder *maskEncoder) IsEmpty(ptr unsafe.Pointer) bool {
+	return encoder.encoder.IsEmpty(ptr)
+}
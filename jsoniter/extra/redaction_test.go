@@ -0,0 +1,108 @@
+package extra
+
+import (
+	"testing"
+
+	"github.com/zhangdapeng520/zdpgo_json/jsoniter"
+)
+
+type redactedCard struct {
+	Number string `json:"number" mask:"last4"`
+	Holder string `json:"holder" mask:"first1"`
+	CVV    int    `json:"cvv,redact"`
+}
+
+type redactedUser struct {
+	Name     string         `json:"name"`
+	Password string         `json:"password,redact"`
+	Token    *string        `json:"token,omitempty,redact"`
+	PIN      string         `json:"pin" mask:"all"`
+	Note     string         `json:"note" mask:"bogus"`
+	Phone    *string        `json:"phone" mask:"last2"`
+	Short    string         `json:"short" mask:"last4"`
+	Card     redactedCard   `json:"card"`
+	Cards    []redactedCard `json:"cards"`
+}
+
+func TestRedactionExtension(t *testing.T) {
+	token, phone := "abc", "13800138000"
+	user := redactedUser{
+		Name:     "Tom",
+		Password: "secret",
+		Token:    &token,
+		PIN:      "1234",
+		Note:     "private",
+		Phone:    &phone,
+		Short:    "abc",
+		Card:     redactedCard{"4111111111111111", "Tom", 123},
+		Cards:    []redactedCard{{"5500000000000004", "李雷", 456}},
+	}
+	tests := []struct {
+		name      string
+		extension *RedactionExtension
+		value     interface{}
+		output    string
+	}{
+		{"defaults", &RedactionExtension{}, user,
+			`{"name":"Tom","password":"[REDACTED]","token":"[REDACTED]","pin":"****","note":"[REDACTED]",` +
+				`"phone":"*********00","short":"***",` +
+				`"card":{"number":"************1111","holder":"T**","cvv":"[REDACTED]"},` +
+				`"cards":[{"number":"************0004","holder":"李*","cvv":"[REDACTED]"}]}`},
+		{"replacement and mask char", &RedactionExtension{Replacement: "***", MaskChar: '#'},
+			redactedCard{"4111111111111111", "Tom", 123},
+			`{"number":"############1111","holder":"T##","cvv":"***"}`},
+		{"nil and empty values", &RedactionExtension{}, redactedUser{},
+			`{"name":"","password":"[REDACTED]","pin":"","note":"[REDACTED]","phone":null,"short":"",` +
+				`"card":{"number":"","holder":"","cvv":"[REDACTED]"},"cards":null}`},
+		{"pointer", &RedactionExtension{}, &redactedCard{"1234", "A", 1},
+			`{"number":"****","holder":"*","cvv":"[REDACTED]"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := jsoniter.Config{}.Froze()
+			api.RegisterExtension(tt.extension)
+			output, err := api.MarshalToString(tt.value)
+			if err != nil || output != tt.output {
+				t.Fatalf("expected %s, got %s %v", tt.output, output, err)
+			}
+		})
+	}
+}
+
+func TestRedactionDecodeUnchanged(t *testing.T) {
+	api := jsoniter.Config{}.Froze()
+	api.RegisterExtension(&RedactionExtension{})
+	var card redactedCard
+	if err := api.UnmarshalFromString(`{"number":"4111","holder":"Tom","cvv":123}`, &card); err != nil {
+		t.Fatal(err)
+	}
+	if card != (redactedCard{"4111", "Tom", 123}) {
+		t.Fatalf("%+v", card)
+	}
+	// other APIs are not redacted
+	if output, _ := jsoniter.MarshalToString(card); output != `{"number":"4111","holder":"Tom","cvv":123}` {
+		t.Fatal(output)
+	}
+}
+
+func TestParseMask(t *testing.T) {
+	tests := []struct {
+		mask    string
+		keep    int
+		fromEnd bool
+		ok      bool
+	}{
+		{"all", 0, false, true},
+		{"last4", 4, true, true},
+		{"first2", 2, false, true},
+		{"last", 0, true, false},
+		{"first-1", -1, false, false},
+		{"middle", 0, false, false},
+	}
+	for _, tt := range tests {
+		keep, fromEnd, ok := parseMask(tt.mask)
+		if ok != tt.ok || (ok && (keep != tt.keep || fromEnd != tt.fromEnd)) {
+			t.Fatalf("%s: got %d %v %v", tt.mask, keep, fromEnd, ok)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package jsoniter
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// The errors of the StrictIJSON mode, wrapped by IJSONError
+var (
+	ErrInvalidUTF8   = errors.New("invalid UTF-8")
+	ErrLoneSurrogate = errors.New("lone surrogate")
+	ErrNumberRange   = errors.New("number out of double range")
+)
+
+// IJSONError is reported when the input breaks the rules of I-JSON
+// (RFC 7493) that StrictIJSON enforces. Duplicate keys are reported as
+// DuplicateKeyError.
+type IJSONError struct {
+	Err    error // ErrInvalidUTF8, ErrLoneSurrogate or ErrNumberRange
+	Offset int   // the byte offset of the input
+	msg    string
+}
+
+func (err *IJSONError) Error() string {
+	return err.msg
+}
+
+func (err *IJSONError) Unwrap() error {
+	return err.Err
+}
+
+func (iter *Iterator) reportIJSON(err error, offset int) {
+	if iter.Error != nil && iter.Error != io.EOF {
+		return
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	iter.Error = nil
+	iter.ReportError("StrictIJSON", err.Error()+" at offset "+strconv.Itoa(offset))
+	iter.Error = &IJSONError{Err: err, Offset: offset, msg: iter.Error.Error()}
+}
+
+// checkUTF8 returns the string read from the input at offset, with the
+// invalid bytes replaced by U+FFFD or reported, as the config says
+func (iter *Iterator) checkUTF8(str string, offset int) string {
+	if utf8.ValidString(str) {
+		return str
+	}
+	if iter.cfg.replaceInvalidUTF8 {
+		return replaceInvalidUTF8(str)
+	}
+	for i := 0; i < len(str); {
+		r, size := utf8.DecodeRuneInString(str[i:])
+		if r == utf8.RuneError && size == 1 {
+			iter.reportIJSON(ErrInvalidUTF8, offset+i)
+			return ""
+		}
+		i += size
+	}
+	return str
+}
+
+// replaceInvalidUTF8 replaces each invalid byte with U+FFFD, like
+// encoding/json does
+func replaceInvalidUTF8(str string) string {
+	buf := make([]byte, 0, len(str)+8)
+	for i := 0; i < len(str); {
+		r, size := utf8.DecodeRuneInString(str[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, "\uFFFD"...)
+		} else {
+			buf = append(buf, str[i:i+size]...)
+		}
+		i += size
+	}
+	return string(buf)
+}
+
+// appendUTF8 appends the raw byte c, that starts a multi byte sequence, to
+// the string of readStringSlowPath. The whole sequence is checked when it
+// is in the buffer, otherwise the string is checked at its end.
+func (iter *Iterator) appendUTF8(str []byte, c byte) []byte {
+	start := iter.head - 1
+	if start < 0 || !utf8.FullRune(iter.buf[start:iter.tail]) {
+		return append(str, c)
+	}
+	r, size := utf8.DecodeRune(iter.buf[start:iter.tail])
+	if r == utf8.RuneError && size == 1 {
+		if iter.cfg.replaceInvalidUTF8 {
+			return append(str, "\uFFFD"...)
+		}
+		iter.reportIJSON(ErrInvalidUTF8, iter.consumed+start)
+		return str
+	}
+	iter.head = start + size
+	return append(str, iter.buf[start:iter.head]...)
+}
+
+// loneSurrogate reports the \u escape at offset when StrictIJSON rejects
+// lone surrogates, otherwise they become U+FFFD
+func (iter *Iterator) loneSurrogate(offset int) {
+	if iter.cfg.strictIJSON && !iter.cfg.replaceInvalidUTF8 {
+		iter.reportIJSON(ErrLoneSurrogate, offset)
+	}
+}
+
+// checkNumberRange reports a number that is out of the double range
+func (iter *Iterator) checkNumberRange(number string, offset int) {
+	if f, _ := strconv.ParseFloat(number, 64); math.IsInf(f, 0) {
+		iter.reportIJSON(ErrNumberRange, offset)
+	}
+}
@@ -0,0 +1,81 @@
+package jsoniter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStrictIJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   error // nil when the input is valid I-JSON
+	}{
+		{"valid", `{"a":["é",1.5e300,"\ud83d\ude00"]}`, nil},
+		{"invalid utf-8", "{\"a\":\"\xff\"}", ErrInvalidUTF8},
+		{"invalid utf-8 in key", "{\"\xc3\x28\":1}", ErrInvalidUTF8},
+		{"invalid utf-8 after escape", "[\"\\n\xff\"]", ErrInvalidUTF8},
+		{"lone high surrogate", `["\ud800"]`, ErrLoneSurrogate},
+		{"lone low surrogate", `{"a":"x\udc00"}`, ErrLoneSurrogate},
+		{"number out of range", `[1e400]`, ErrNumberRange},
+		{"negative number out of range", `{"a":-1e400}`, ErrNumberRange},
+		{"duplicate key", `{"a":1,"a":2}`, ErrDuplicateKey},
+	}
+	strict := Config{StrictIJSON: true}.Froze()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := func(what string, err error) {
+				t.Helper()
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("%s: expected %v, got %v", what, tt.err, err)
+				}
+			}
+			var v interface{}
+			check("Unmarshal", strict.UnmarshalFromString(tt.input, &v))
+			var m map[string]interface{}
+			var l []interface{}
+			if tt.input[0] == '{' {
+				check("Unmarshal map", strict.UnmarshalFromString(tt.input, &m))
+			} else {
+				check("Unmarshal slice", strict.UnmarshalFromString(tt.input, &l))
+			}
+			check("Get", strict.Get([]byte(tt.input)).LastError())
+			if valid := strict.Valid([]byte(tt.input)); valid != (tt.err == nil) {
+				t.Fatalf("Valid is %v", valid)
+			}
+			if tt.err != ErrDuplicateKey {
+				var ijsonErr *IJSONError
+				err := strict.UnmarshalFromString(tt.input, &v)
+				if tt.err != nil && (!errors.As(err, &ijsonErr) || ijsonErr.Offset <= 0) {
+					t.Fatalf("expected an IJSONError with an offset, got %#v", err)
+				}
+			}
+			// the default config accepts all of them
+			if err := UnmarshalFromString(tt.input, &v); err != nil && tt.err != ErrNumberRange {
+				t.Fatalf("default config: %v", err)
+			}
+		})
+	}
+}
+
+func TestReplaceInvalidUTF8(t *testing.T) {
+	api := Config{ReplaceInvalidUTF8: true}.Froze()
+	tests := map[string]string{
+		"\"a\xffb\"":       "a\uFFFDb",
+		`"\ud800x"`:        "\uFFFDx",
+		"\"\\t\xc3\x28\"":  "\t\uFFFD(",
+		`"\ud83d\ude00"`:   "\U0001F600",
+		"\"\xe2\x82\xac\"": "€",
+	}
+	for input, expected := range tests {
+		var s string
+		if err := api.UnmarshalFromString(input, &s); err != nil || s != expected {
+			t.Fatalf("%q: expected %q, got %q %v", input, expected, s, err)
+		}
+	}
+	strict := Config{StrictIJSON: true, ReplaceInvalidUTF8: true}.Froze()
+	var s string
+	if err := strict.UnmarshalFromString("\"\xff\"", &s); err != nil || s != "\uFFFD" {
+		t.Fatalf("ReplaceInvalidUTF8 wins over StrictIJSON: %q %v", s, err)
+	}
+}
@@ -75,10 +75,15 @@ type Iterator struct {
 	head             int
 	tail             int
 	depth            int
+	consumed         int      // the bytes read from the reader before buf
+	keyPath          []string // the path being read while duplicate keys are checked
 	captureStartedAt int
 	captured         []byte
 	Error            error
 	Attachment       interface{} // open for customized decoder
+	// OnDeprecated is called when a struct field tagged with deprecated is
+	// decoded, before its value is read
+	OnDeprecated func(iter *Iterator, field DeprecatedField)
 }
 
 // NewIterator creates an empty Iterator instance
@@ -107,7 +112,7 @@ func Parse(cfg API, reader io.Reader, bufSize int) *Iterator {
 
 // ParseBytes creates an Iterator instance from byte array
 func ParseBytes(cfg API, input []byte) *Iterator {
-	return &Iterator{
+	iter := &Iterator{
 		cfg:    cfg.(*frozenConfig),
 		reader: nil,
 		buf:    input,
@@ -115,6 +120,8 @@ func ParseBytes(cfg API, input []byte) *Iterator {
 		tail:   len(input),
 		depth:  0,
 	}
+	iter.checkBytes(len(input))
+	return iter
 }
 
 // ParseString creates an Iterator instance from string
@@ -133,6 +140,8 @@ func (iter *Iterator) Reset(reader io.Reader) *Iterator {
 	iter.head = 0
 	iter.tail = 0
 	iter.depth = 0
+	iter.consumed = 0
+	iter.keyPath = iter.keyPath[:0]
 	return iter
 }
 
@@ -143,6 +152,9 @@ func (iter *Iterator) ResetBytes(input []byte) *Iterator {
 	iter.head = 0
 	iter.tail = len(input)
 	iter.depth = 0
+	iter.consumed = 0
+	iter.keyPath = iter.keyPath[:0]
+	iter.checkBytes(len(input))
 	return iter
 }
 
@@ -272,9 +284,10 @@ func (iter *Iterator) loadMore() bool {
 				return false
 			}
 		} else {
+			iter.consumed += iter.tail
 			iter.head = 0
 			iter.tail = n
-			return true
+			return iter.checkBytes(iter.consumed + n)
 		}
 	}
 }
@@ -313,6 +326,9 @@ func (iter *Iterator) Read() interface{} {
 		})
 		return arr
 	case ObjectValue:
+		if iter.cfg.configBeforeFrozen.UseOrderedMap {
+			return iter.readOrdered()
+		}
 		obj := map[string]interface{}{}
 		iter.ReadMapCB(func(Iter *Iterator, field string) bool {
 			var elem interface{}
@@ -332,10 +348,10 @@ const maxDepth = 10000
 
 func (iter *Iterator) incrementDepth() (success bool) {
 	iter.depth++
-	if iter.depth <= maxDepth {
+	if iter.depth <= iter.cfg.maxDepth {
 		return true
 	}
-	iter.ReportError("incrementDepth", "exceeded max depth")
+	iter.reportLimit("MaxDepth", "depth", iter.cfg.maxDepth)
 	return false
 }
 
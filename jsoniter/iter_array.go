@@ -34,13 +34,17 @@ func (iter *Iterator) ReadArrayCB(callback func(*Iterator) bool) (ret bool) {
 		c = iter.nextToken()
 		if c != ']' {
 			iter.unreadByte()
-			if !callback(iter) {
+			if !iter.callElement(0, callback) {
 				iter.decrementDepth()
 				return false
 			}
 			c = iter.nextToken()
-			for c == ',' {
-				if !callback(iter) {
+			for elems := 2; c == ','; elems++ {
+				if !iter.checkArrayElements(elems) {
+					iter.decrementDepth()
+					return false
+				}
+				if !iter.callElement(elems-1, callback) {
 					iter.decrementDepth()
 					return false
 				}
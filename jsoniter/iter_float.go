@@ -77,6 +77,9 @@ func (iter *Iterator) ReadFloat32() (ret float32) {
 }
 
 func (iter *Iterator) readPositiveFloat32() (ret float32) {
+	if iter.cfg.maxNumberLength > 0 {
+		defer iter.checkNumberEnd(iter.consumed + iter.head)
+	}
 	i := iter.head
 	// first char
 	if i == iter.tail {
@@ -172,19 +175,33 @@ load_loop:
 				break load_loop
 			}
 		}
-		if !iter.loadMore() {
+		if !iter.checkNumberLength(digitsOf(str)) || !iter.loadMore() {
 			break
 		}
 	}
+	if !iter.checkNumberLength(digitsOf(str)) {
+		return
+	}
 	if iter.Error != nil && iter.Error != io.EOF {
 		return
 	}
 	if len(str) == 0 {
 		iter.ReportError("readNumberAsString", "invalid number")
 	}
+	if iter.cfg.strictIJSON {
+		iter.checkNumberRange(string(str), iter.consumed+iter.head-len(str))
+	}
 	return *(*string)(unsafe.Pointer(&str))
 }
 
+// digitsOf returns the length of a number without its sign
+func digitsOf(number []byte) int {
+	if len(number) > 0 && number[0] == '-' {
+		return len(number) - 1
+	}
+	return len(number)
+}
+
 func (iter *Iterator) readFloat32SlowPath() (ret float32) {
 	str := iter.readNumberAsString()
 	if iter.Error != nil && iter.Error != io.EOF {
@@ -214,6 +231,9 @@ func (iter *Iterator) ReadFloat64() (ret float64) {
 }
 
 func (iter *Iterator) readPositiveFloat64() (ret float64) {
+	if iter.cfg.maxNumberLength > 0 {
+		defer iter.checkNumberEnd(iter.consumed + iter.head)
+	}
 	i := iter.head
 	// first char
 	if i == iter.tail {
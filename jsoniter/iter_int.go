@@ -120,6 +120,9 @@ func (iter *Iterator) ReadUint32() (ret uint32) {
 }
 
 func (iter *Iterator) readUint32(c byte) (ret uint32) {
+	if iter.cfg.maxNumberLength > 0 {
+		defer iter.checkNumberEnd(iter.consumed + iter.head - 1)
+	}
 	ind := intDigits[c]
 	if ind == 0 {
 		iter.assertInteger()
@@ -242,6 +245,9 @@ func (iter *Iterator) ReadUint64() uint64 {
 }
 
 func (iter *Iterator) readUint64(c byte) (ret uint64) {
+	if iter.cfg.maxNumberLength > 0 {
+		defer iter.checkNumberEnd(iter.consumed + iter.head - 1)
+	}
 	ind := intDigits[c]
 	if ind == 0 {
 		iter.assertInteger()
@@ -53,13 +53,19 @@ func (iter *Iterator) readFieldHash() int64 {
 		iter.ReportError("readFieldHash", `expect ", but found `+string([]byte{c}))
 		return 0
 	}
+	n := 0 // the bytes of the field name in the previous buffers
 	for {
 		for i := iter.head; i < iter.tail; i++ {
 			// require ascii string and no escape
 			b := iter.buf[i]
 			if b == '\\' {
+				n += i - iter.head
 				iter.head = i
-				for _, b := range iter.readStringSlowPath() {
+				rest := iter.readStringSlowPath()
+				if !iter.checkStringLength(n + len(rest)) {
+					return 0
+				}
+				for _, b := range rest {
 					if 'A' <= b && b <= 'Z' && !iter.cfg.caseSensitive {
 						b += 'a' - 'A'
 					}
@@ -74,6 +80,9 @@ func (iter *Iterator) readFieldHash() int64 {
 				return hash
 			}
 			if b == '"' {
+				if !iter.checkStringLength(n + i - iter.head) {
+					return 0
+				}
 				iter.head = i + 1
 				c = iter.nextToken()
 				if c != ':' {
@@ -88,6 +97,7 @@ func (iter *Iterator) readFieldHash() int64 {
 			hash ^= int64(b)
 			hash *= 0x1000193
 		}
+		n += iter.tail - iter.head
 		if !iter.loadMore() {
 			iter.ReportError("readFieldHash", `incomplete field name`)
 			return 0
@@ -115,6 +125,7 @@ func (iter *Iterator) ReadObjectCB(callback func(*Iterator, string) bool) bool {
 		if !iter.incrementDepth() {
 			return false
 		}
+		seen := iter.newKeySet()
 		c = iter.nextToken()
 		if c == '"' {
 			iter.unreadByte()
@@ -123,18 +134,22 @@ func (iter *Iterator) ReadObjectCB(callback func(*Iterator, string) bool) bool {
 			if c != ':' {
 				iter.ReportError("ReadObject", "expect : after object field, but found "+string([]byte{c}))
 			}
-			if !callback(iter, field) {
+			if !iter.callField(seen, field, callback) {
 				iter.decrementDepth()
 				return false
 			}
 			c = iter.nextToken()
-			for c == ',' {
+			for keys := 2; c == ','; keys++ {
+				if !iter.checkObjectKeys(keys) {
+					iter.decrementDepth()
+					return false
+				}
 				field = iter.ReadString()
 				c = iter.nextToken()
 				if c != ':' {
 					iter.ReportError("ReadObject", "expect : after object field, but found "+string([]byte{c}))
 				}
-				if !callback(iter, field) {
+				if !iter.callField(seen, field, callback) {
 					iter.decrementDepth()
 					return false
 				}
@@ -169,6 +184,7 @@ func (iter *Iterator) ReadMapCB(callback func(*Iterator, string) bool) bool {
 		if !iter.incrementDepth() {
 			return false
 		}
+		seen := iter.newKeySet()
 		c = iter.nextToken()
 		if c == '"' {
 			iter.unreadByte()
@@ -178,19 +194,23 @@ func (iter *Iterator) ReadMapCB(callback func(*Iterator, string) bool) bool {
 				iter.decrementDepth()
 				return false
 			}
-			if !callback(iter, field) {
+			if !iter.callField(seen, field, callback) {
 				iter.decrementDepth()
 				return false
 			}
 			c = iter.nextToken()
-			for c == ',' {
+			for keys := 2; c == ','; keys++ {
+				if !iter.checkObjectKeys(keys) {
+					iter.decrementDepth()
+					return false
+				}
 				field = iter.ReadString()
 				if iter.nextToken() != ':' {
 					iter.ReportError("ReadMapCB", "expect : after object field, but found "+string([]byte{c}))
 					iter.decrementDepth()
 					return false
 				}
-				if !callback(iter, field) {
+				if !iter.callField(seen, field, callback) {
 					iter.decrementDepth()
 					return false
 				}
@@ -3,9 +3,17 @@
 
 package jsoniter
 
-// sloppy but faster implementation, do not validate the input json
+// sloppy but faster implementation, do not validate the input json.
+// The limits, StrictIJSON and the duplicate keys errors of the config can
+// not be checked by the scan, the values are skipped with the readers when
+// they are set.
 
 func (iter *Iterator) skipNumber() {
+	if iter.cfg.checkedSkip {
+		iter.unreadByte()
+		iter.readNumberAsString()
+		return
+	}
 	for {
 		for i := iter.head; i < iter.tail; i++ {
 			c := iter.buf[i]
@@ -22,6 +30,14 @@ func (iter *Iterator) skipNumber() {
 }
 
 func (iter *Iterator) skipArray() {
+	if iter.cfg.checkedSkip {
+		iter.unreadByte()
+		iter.ReadArrayCB(func(iter *Iterator) bool {
+			iter.Skip()
+			return true
+		})
+		return
+	}
 	level := 1
 	if !iter.incrementDepth() {
 		return
@@ -49,6 +65,14 @@ func (iter *Iterator) skipArray() {
 					iter.head = i + 1
 					return
 				}
+			case '{': // the nested objects count for the depth
+				if !iter.incrementDepth() {
+					return
+				}
+			case '}':
+				if !iter.decrementDepth() {
+					return
+				}
 			}
 		}
 		if !iter.loadMore() {
@@ -59,6 +83,14 @@ func (iter *Iterator) skipArray() {
 }
 
 func (iter *Iterator) skipObject() {
+	if iter.cfg.checkedSkip {
+		iter.unreadByte()
+		iter.ReadObjectCB(func(iter *Iterator, field string) bool {
+			iter.Skip()
+			return true
+		})
+		return
+	}
 	level := 1
 	if !iter.incrementDepth() {
 		return
@@ -87,6 +119,14 @@ func (iter *Iterator) skipObject() {
 					iter.head = i + 1
 					return
 				}
+			case '[': // the nested arrays count for the depth
+				if !iter.incrementDepth() {
+					return
+				}
+			case ']':
+				if !iter.decrementDepth() {
+					return
+				}
 			}
 		}
 		if !iter.loadMore() {
@@ -97,6 +137,11 @@ func (iter *Iterator) skipObject() {
 }
 
 func (iter *Iterator) skipString() {
+	if iter.cfg.checkedSkip {
+		iter.unreadByte()
+		iter.ReadString()
+		return
+	}
 	for {
 		end, escaped := iter.findStringEnd()
 		if end == -1 {
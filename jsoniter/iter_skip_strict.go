@@ -6,6 +6,7 @@ package jsoniter
 import (
 	"fmt"
 	"io"
+	"unicode/utf8"
 )
 
 func (iter *Iterator) skipNumber() {
@@ -15,6 +16,10 @@ func (iter *Iterator) skipNumber() {
 			return
 		}
 		iter.ReadFloat64()
+		switch iter.Error.(type) {
+		case *IJSONError, *LimitError:
+			return
+		}
 		if iter.Error != nil && iter.Error != io.EOF {
 			iter.Error = nil
 			iter.ReadBigFloat()
@@ -50,6 +55,11 @@ func (iter *Iterator) trySkipNumber() bool {
 				if iter.head == i {
 					return false // if - without following digits
 				}
+				// the first char was read by Skip
+				iter.checkNumberLength(digitsOf(iter.buf[iter.head-1 : i]))
+				if iter.cfg.strictIJSON {
+					iter.checkNumberRange(string(iter.buf[iter.head-1:i]), iter.consumed+iter.head-1)
+				}
 				iter.head = i
 				return true // must be valid
 			}
@@ -70,6 +80,10 @@ func (iter *Iterator) trySkipString() bool {
 	for i := iter.head; i < iter.tail; i++ {
 		c := iter.buf[i]
 		if c == '"' {
+			iter.checkStringLength(i - iter.head)
+			if iter.cfg.strictIJSON && !utf8.Valid(iter.buf[iter.head:i]) {
+				iter.checkUTF8(string(iter.buf[iter.head:i]), iter.consumed+iter.head)
+			}
 			iter.head = i + 1
 			return true // valid
 		} else if c == '\\' {
@@ -3,6 +3,7 @@ package jsoniter
 import (
 	"fmt"
 	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // ReadString read string from jsoniter
@@ -12,7 +13,13 @@ func (iter *Iterator) ReadString() (ret string) {
 		for i := iter.head; i < iter.tail; i++ {
 			c := iter.buf[i]
 			if c == '"' {
+				if !iter.checkStringLength(i - iter.head) {
+					return
+				}
 				ret = string(iter.buf[iter.head:i])
+				if iter.cfg.validateUTF8 {
+					ret = iter.checkUTF8(ret, iter.consumed+iter.head)
+				}
 				iter.head = i + 1
 				return ret
 			} else if c == '\\' {
@@ -35,17 +42,26 @@ func (iter *Iterator) ReadString() (ret string) {
 func (iter *Iterator) readStringSlowPath() (ret string) {
 	var str []byte
 	var c byte
+	start := iter.consumed + iter.head
 	for iter.Error == nil {
 		c = iter.readByte()
 		if c == '"' {
+			if iter.cfg.validateUTF8 {
+				return iter.checkUTF8(string(str), start)
+			}
 			return string(str)
 		}
 		if c == '\\' {
 			c = iter.readByte()
 			str = iter.readEscapedChar(c, str)
+		} else if c >= utf8.RuneSelf && iter.cfg.validateUTF8 {
+			str = iter.appendUTF8(str, c)
 		} else {
 			str = append(str, c)
 		}
+		if !iter.checkStringLength(len(str)) {
+			return
+		}
 	}
 	iter.ReportError("readStringSlowPath", "unexpected end of input")
 	return
@@ -55,6 +71,7 @@ func (iter *Iterator) readEscapedChar(c byte, str []byte) []byte {
 	switch c {
 	case 'u':
 		r := iter.readU4()
+		offset := iter.consumed + iter.head - 6 // the \u of r
 		if utf16.IsSurrogate(r) {
 			c = iter.readByte()
 			if iter.Error != nil {
@@ -62,6 +79,7 @@ func (iter *Iterator) readEscapedChar(c byte, str []byte) []byte {
 			}
 			if c != '\\' {
 				iter.unreadByte()
+				iter.loneSurrogate(offset)
 				str = appendRune(str, r)
 				return str
 			}
@@ -70,6 +88,7 @@ func (iter *Iterator) readEscapedChar(c byte, str []byte) []byte {
 				return nil
 			}
 			if c != 'u' {
+				iter.loneSurrogate(offset)
 				str = appendRune(str, r)
 				return iter.readEscapedChar(c, str)
 			}
@@ -79,6 +98,7 @@ func (iter *Iterator) readEscapedChar(c byte, str []byte) []byte {
 			}
 			combined := utf16.DecodeRune(r, r2)
 			if combined == '\uFFFD' {
+				iter.loneSurrogate(offset)
 				str = appendRune(str, r)
 				str = appendRune(str, r2)
 			} else {
@@ -120,6 +140,9 @@ func (iter *Iterator) ReadStringAsSlice() (ret []byte) {
 			// require ascii string and no escape
 			// for: field name, base64, number
 			if iter.buf[i] == '"' {
+				if !iter.checkStringLength(i - iter.head) {
+					return
+				}
 				// fast path: reuse the underlying buffer
 				ret = iter.buf[iter.head:i]
 				iter.head = i + 1
@@ -136,6 +159,9 @@ func (iter *Iterator) ReadStringAsSlice() (ret []byte) {
 				return copied
 			}
 			copied = append(copied, c)
+			if !iter.checkStringLength(len(copied)) {
+				return
+			}
 		}
 		return copied
 	}
@@ -0,0 +1,90 @@
+package jsoniter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrLimitExceeded is wrapped by the errors of the Config limits,
+// check it with errors.Is
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// LimitError is reported when the input exceeds one of the Config limits.
+// The rest of the input is not read after a limit is exceeded.
+type LimitError struct {
+	Limit  string // the Config field, such as "MaxDepth"
+	Max    int
+	Offset int // the byte offset of the input where the limit was exceeded
+	msg    string
+}
+
+func (err *LimitError) Error() string {
+	return err.msg
+}
+
+func (err *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// reportLimit reports a LimitError and drops the rest of the input, so that
+// hostile payloads are not read any further
+func (iter *Iterator) reportLimit(limit string, what string, max int) {
+	if iter.Error != nil && iter.Error != io.EOF {
+		return
+	}
+	offset := iter.consumed + iter.head
+	iter.Error = nil
+	iter.ReportError(limit, fmt.Sprintf("%v: max %s is %d", ErrLimitExceeded, what, max))
+	iter.Error = &LimitError{Limit: limit, Max: max, Offset: offset, msg: iter.Error.Error()}
+	iter.head = iter.tail
+	iter.reader = nil
+}
+
+func (iter *Iterator) checkBytes(n int) bool {
+	if max := iter.cfg.maxBytes; max > 0 && n > max {
+		iter.reportLimit("MaxBytes", "bytes", max)
+		return false
+	}
+	return true
+}
+
+func (iter *Iterator) checkStringLength(n int) bool {
+	if max := iter.cfg.maxStringLength; max > 0 && n > max {
+		iter.reportLimit("MaxStringLength", "string length", max)
+		return false
+	}
+	return true
+}
+
+func (iter *Iterator) checkNumberLength(n int) bool {
+	if max := iter.cfg.maxNumberLength; max > 0 && n > max {
+		iter.reportLimit("MaxNumberLength", "number length", max)
+		return false
+	}
+	return true
+}
+
+// checkNumberEnd checks the length of the number read from the offset start,
+// for the readers that do not keep the text of the number
+func (iter *Iterator) checkNumberEnd(start int) {
+	iter.checkNumberLength(iter.consumed + iter.head - start)
+}
+
+// checkArrayElements is called with the count of each element read
+func (iter *Iterator) checkArrayElements(n int) bool {
+	if max := iter.cfg.maxArrayElements; max > 0 && n > max {
+		iter.reportLimit("MaxArrayElements", "array elements", max)
+		return false
+	}
+	return true
+}
+
+// checkObjectKeys is called with the count of each key read
+func (iter *Iterator) checkObjectKeys(n int) bool {
+	if max := iter.cfg.maxObjectKeys; max > 0 && n > max {
+		iter.reportLimit("MaxObjectKeys", "object keys", max)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package jsoniter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		input  string
+		limit  string
+	}{
+		{"depth", Config{MaxDepth: 2}, `{"a":[[1]]}`, "MaxDepth"},
+		{"bytes", Config{MaxBytes: 8}, `{"a":"bcdef"}`, "MaxBytes"},
+		{"string", Config{MaxStringLength: 3}, `{"a":"bcde"}`, "MaxStringLength"},
+		{"escaped string", Config{MaxStringLength: 3}, `{"a":"bcde"}`, "MaxStringLength"},
+		{"key", Config{MaxStringLength: 3}, `{"abcd":1}`, "MaxStringLength"},
+		{"number", Config{MaxNumberLength: 3}, `{"a":12345}`, "MaxNumberLength"},
+		{"negative number", Config{MaxNumberLength: 3}, `{"a":-1234}`, "MaxNumberLength"},
+		{"long number", Config{MaxNumberLength: 3}, `{"a":1.2345e10}`, "MaxNumberLength"},
+		{"array", Config{MaxArrayElements: 2}, `{"a":[1,2,3]}`, "MaxArrayElements"},
+		{"object", Config{MaxObjectKeys: 2}, `{"a":{"b":1,"c":2,"d":3}}`, "MaxObjectKeys"},
+	}
+	check := func(t *testing.T, what string, err error, limit string) {
+		t.Helper()
+		var limitErr *LimitError
+		if !errors.Is(err, ErrLimitExceeded) || !errors.As(err, &limitErr) || limitErr.Limit != limit {
+			t.Fatalf("%s: expected a %s error, got %v", what, limit, err)
+		}
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := tt.config.Froze()
+			var v interface{}
+			check(t, "Unmarshal", api.UnmarshalFromString(tt.input, &v), tt.limit)
+			var s struct {
+				A interface{} `json:"a"`
+			}
+			check(t, "Unmarshal struct", api.UnmarshalFromString(tt.input, &s), tt.limit)
+			if api.Valid([]byte(tt.input)) {
+				t.Fatal("Valid: the input exceeds a limit")
+			}
+			check(t, "Get", api.Get([]byte(tt.input)).LastError(), tt.limit)
+			check(t, "Get path", api.Get([]byte(tt.input), "a").LastError(), tt.limit)
+			iter := api.BorrowIterator([]byte(tt.input))
+			any := iter.ReadAny()
+			api.ReturnIterator(iter)
+			check(t, "ReadAny", any.LastError(), tt.limit)
+			decoder := api.NewDecoder(strings.NewReader(tt.input))
+			check(t, "Decoder", decoder.Decode(&v), tt.limit)
+
+			// within the limits
+			relaxed := Config{}.Froze()
+			if err := relaxed.UnmarshalFromString(tt.input, &v); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestLimitsWithin(t *testing.T) {
+	api := Config{
+		MaxDepth:         3,
+		MaxBytes:         64,
+		MaxStringLength:  4,
+		MaxNumberLength:  4,
+		MaxArrayElements: 3,
+		MaxObjectKeys:    2,
+	}.Froze()
+	input := `{"ab":[1,2.5,"abcd"],"c":{"d":[-123]}}`
+	var v interface{}
+	if err := api.UnmarshalFromString(input, &v); err != nil {
+		t.Fatal(err)
+	}
+	if !api.Valid([]byte(input)) {
+		t.Fatal("Valid")
+	}
+	if any := api.Get([]byte(input), "c", "d", 0); any.LastError() != nil || any.ToInt() != -123 {
+		t.Fatalf("Get %v %v", any.ToInt(), any.LastError())
+	}
+}
+
+func TestLimitErrorOffset(t *testing.T) {
+	api := Config{MaxArrayElements: 2}.Froze()
+	err := api.UnmarshalFromString(`[1,2,3,4]`, new([]int))
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Max != 2 || limitErr.Offset <= 0 {
+		t.Fatalf("%#v", err)
+	}
+}
+
+func TestNumberLengthTyped(t *testing.T) {
+	api := Config{MaxNumberLength: 3}.Froze()
+	for _, target := range []interface{}{new(int), new(int8), new(int64), new(float32), new(float64), new(Number)} {
+		if err := api.UnmarshalFromString(`-123`, target); err != nil {
+			t.Fatalf("%T: %v", target, err)
+		}
+	}
+	targets := []interface{}{new(int), new(int8), new(uint32), new(uint64), new(float32), new(float64), new(Number)}
+	for _, target := range targets {
+		if err := api.UnmarshalFromString(`123`, target); err != nil {
+			t.Fatalf("%T: %v", target, err)
+		}
+		if err := api.UnmarshalFromString(`1000`, target); !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("%T: expected a limit error, got %v", target, err)
+		}
+	}
+}
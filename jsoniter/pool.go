@@ -26,6 +26,8 @@ func (cfg *frozenConfig) ReturnStream(stream *Stream) {
 	stream.out = nil
 	stream.Error = nil
 	stream.Attachment = nil
+	stream.view = ""
+	stream.fields = nil
 	cfg.streamPool.Put(stream)
 }
 
@@ -38,5 +40,6 @@ func (cfg *frozenConfig) BorrowIterator(data []byte) *Iterator {
 func (cfg *frozenConfig) ReturnIterator(iter *Iterator) {
 	iter.Error = nil
 	iter.Attachment = nil
+	iter.OnDeprecated = nil
 	cfg.iteratorPool.Put(iter)
 }
@@ -39,6 +39,7 @@ type ctx struct {
 	prefix   string
 	encoders map[reflect2.Type]ValEncoder
 	decoders map[reflect2.Type]ValDecoder
+	view     string // the view of MarshalView, empty to encode all the fields
 }
 
 func (b *ctx) caseSensitive() bool {
@@ -55,6 +56,7 @@ func (b *ctx) append(prefix string) *ctx {
 		prefix:       b.prefix + " " + prefix,
 		encoders:     b.encoders,
 		decoders:     b.decoders,
+		view:         b.view,
 	}
 }
 
@@ -89,6 +91,11 @@ func (stream *Stream) WriteVal(val interface{}) {
 		stream.WriteNil()
 		return
 	}
+	if stream.view != "" {
+		encoder := stream.cfg.encoderOfView(reflect2.TypeOf(val), stream.view)
+		encoder.Encode(reflect2.PtrOf(val), stream)
+		return
+	}
 	cacheKey := reflect2.RTypeOf(val)
 	encoder := stream.cfg.getEncoderFromCache(cacheKey)
 	if encoder == nil {
@@ -153,6 +160,10 @@ func _createDecoderOfType(ctx *ctx, typ reflect2.Type) ValDecoder {
 	if decoder != nil {
 		return decoder
 	}
+	decoder = createDecoderOfOrderedMap(ctx, typ)
+	if decoder != nil {
+		return decoder
+	}
 	decoder = createDecoderOfMarshaler(ctx, typ)
 	if decoder != nil {
 		return decoder
@@ -255,6 +266,10 @@ func _createEncoderOfType(ctx *ctx, typ reflect2.Type) ValEncoder {
 	if encoder != nil {
 		return encoder
 	}
+	encoder = createEncoderOfOrderedMap(ctx, typ)
+	if encoder != nil {
+		return encoder
+	}
 	encoder = createEncoderOfMarshaler(ctx, typ)
 	if encoder != nil {
 		return encoder
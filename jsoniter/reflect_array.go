@@ -64,7 +64,7 @@ type arrayDecoder struct {
 func (decoder *arrayDecoder) Decode(ptr unsafe.Pointer, iter *Iterator) {
 	decoder.doDecode(ptr, iter)
 	if iter.Error != nil && iter.Error != io.EOF {
-		iter.Error = fmt.Errorf("%v: %s", decoder.arrayType, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v: %w", decoder.arrayType, iter.Error)
 	}
 }
 
@@ -85,9 +85,16 @@ func (decoder *arrayDecoder) doDecode(ptr unsafe.Pointer, iter *Iterator) {
 	}
 	iter.unreadByte()
 	elemPtr := arrayType.UnsafeGetIndex(ptr, 0)
+	iter.pushIndex(0)
 	decoder.elemDecoder.Decode(elemPtr, iter)
+	iter.popIndex()
 	length := 1
+	elems := 1
 	for c = iter.nextToken(); c == ','; c = iter.nextToken() {
+		elems++
+		if !iter.checkArrayElements(elems) {
+			return
+		}
 		if length >= arrayType.Len() {
 			iter.Skip()
 			continue
@@ -95,7 +102,9 @@ func (decoder *arrayDecoder) doDecode(ptr unsafe.Pointer, iter *Iterator) {
 		idx := length
 		length += 1
 		elemPtr = arrayType.UnsafeGetIndex(ptr, idx)
+		iter.pushIndex(idx)
 		decoder.elemDecoder.Decode(elemPtr, iter)
+		iter.popIndex()
 	}
 	if c != ']' {
 		iter.ReportError("decode array", "expect ], but found "+string([]byte{c}))
@@ -0,0 +1,193 @@
+package jsoniter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/zhangdapeng520/zdpgo_json/reflect2"
+)
+
+// FillDefaults sets the zero fields of the struct v points to, and of its
+// nested structs, to the value of their default tag. A default is the text
+// itself for the string fields, such as `default:"localhost"`, and a JSON
+// literal for the others, such as `default:"8080"` or `default:"[1,2]"`.
+// Unmarshal does the same for the keys missing from the input.
+func FillDefaults(v interface{}) error {
+	return ConfigDefault.(*frozenConfig).FillDefaults(v)
+}
+
+// FillDefaults is FillDefaults that decodes the defaults with the config
+func (cfg *frozenConfig) FillDefaults(v interface{}) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return errors.New("FillDefaults: expect a non-nil pointer")
+	}
+	if val.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return cfg.fillStructDefaults(val.Elem())
+}
+
+func (cfg *frozenConfig) fillStructDefaults(v reflect.Value) error {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		if err := cfg.fillFieldDefault(v.Field(i), field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillFieldDefault sets the default of the field if it is zero, otherwise
+// fills the defaults of the struct it holds
+func (cfg *frozenConfig) fillFieldDefault(v reflect.Value, field reflect.StructField) error {
+	if literal, found := field.Tag.Lookup("default"); found {
+		if !v.CanSet() || !v.IsZero() {
+			return nil
+		}
+		if err := cfg.Unmarshal(defaultLiteral(field.Type, literal), v.Addr().Interface()); err != nil {
+			return fmt.Errorf("default of %s: %w", field.Name, err)
+		}
+		return nil
+	}
+	switch {
+	case v.Kind() == reflect.Struct:
+		return cfg.fillStructDefaults(v)
+	case v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct:
+		return cfg.fillStructDefaults(v.Elem())
+	}
+	return nil
+}
+
+// defaultLiteral returns the JSON of a default tag, the string fields take
+// the text as it is
+func defaultLiteral(typ reflect.Type, literal string) []byte {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.String {
+		quoted, _ := json.Marshal(literal)
+		return quoted
+	}
+	return []byte(literal)
+}
+
+// hasDefaults tells if the field has a default tag, or holds a struct with
+// defaults that are filled when the key is missing
+func hasDefaults(field reflect.StructField) bool {
+	if _, found := field.Tag.Lookup("default"); found {
+		return true
+	}
+	if field.Type.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < field.Type.NumField(); i++ {
+		nested := field.Type.Field(i)
+		if (nested.PkgPath == "" || nested.Anonymous) && hasDefaults(nested) {
+			return true
+		}
+	}
+	return false
+}
+
+// structDefaults are the fields of a struct with defaults, that
+// generalStructDecoder fills when their keys are missing
+type structDefaults struct {
+	typ     reflect.Type
+	index   map[*structFieldDecoder]int
+	entries []fieldDefault
+}
+
+type fieldDefault struct {
+	levels []int
+	field  reflect.StructField
+}
+
+// defaultsOfStruct returns the defaults of a struct, bindings is the result
+// of decoderOfStruct
+func defaultsOfStruct(typ reflect2.Type, bindings map[string]*Binding) *structDefaults {
+	var defaults *structDefaults
+	for _, binding := range bindings {
+		field, ok := fieldOfLevels(typ.Type1(), binding.levels)
+		if !ok || !hasDefaults(field) {
+			continue
+		}
+		if defaults == nil {
+			defaults = &structDefaults{typ: typ.Type1(), index: map[*structFieldDecoder]int{}}
+		}
+		decoder := binding.Decoder.(*structFieldDecoder)
+		if _, found := defaults.index[decoder]; found {
+			// the same field under another name
+			continue
+		}
+		defaults.index[decoder] = len(defaults.entries)
+		defaults.entries = append(defaults.entries, fieldDefault{binding.levels, field})
+	}
+	return defaults
+}
+
+// fieldOfLevels returns the struct field at the levels of a binding
+func fieldOfLevels(typ reflect.Type, levels []int) (reflect.StructField, bool) {
+	var field reflect.StructField
+	for _, level := range levels {
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || level >= typ.NumField() {
+			return field, false
+		}
+		field = typ.Field(level)
+		typ = field.Type
+	}
+	return field, len(levels) > 0
+}
+
+// indexOf returns the entry of a field decoder, defaults may be nil
+func (defaults *structDefaults) indexOf(decoder *structFieldDecoder) (int, bool) {
+	if defaults == nil || decoder == nil {
+		return 0, false
+	}
+	i, found := defaults.index[decoder]
+	return i, found
+}
+
+// fill sets the defaults of the fields whose keys were not decoded
+func (defaults *structDefaults) fill(ptr unsafe.Pointer, iter *Iterator, decoded []bool) {
+	obj := reflect.NewAt(defaults.typ, ptr).Elem()
+	for i, entry := range defaults.entries {
+		if decoded[i] {
+			continue
+		}
+		field, ok := fieldByLevels(obj, entry.levels)
+		if !ok {
+			continue
+		}
+		if err := iter.cfg.fillFieldDefault(field, entry.field); err != nil {
+			iter.ReportError("default", err.Error())
+			return
+		}
+	}
+}
+
+// fieldByLevels returns the field at the levels of a binding, it is not
+// found when an embedded pointer on the way is nil
+func fieldByLevels(obj reflect.Value, levels []int) (reflect.Value, bool) {
+	field := obj
+	for _, level := range levels {
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				return field, false
+			}
+			field = field.Elem()
+		}
+		field = field.Field(level)
+	}
+	return field, true
+}
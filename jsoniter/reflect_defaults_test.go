@@ -0,0 +1,86 @@
+package jsoniter
+
+import (
+	"strings"
+	"testing"
+)
+
+type defaultsInner struct {
+	Name string `json:"name" default:"app"`
+	Pool int    `json:"pool" default:"10"`
+}
+
+type defaultsConfig struct {
+	Host    string         `json:"host" default:"localhost"`
+	Port    int            `json:"port" default:"8080"`
+	Debug   bool           `json:"debug" default:"true"`
+	Ratio   *float64       `json:"ratio" default:"0.5"`
+	Tags    []string       `json:"tags" default:"[\"a\",\"b\"]"`
+	DB      defaultsInner  `json:"db"`
+	Replica *defaultsInner `json:"replica"`
+	Plain   int            `json:"plain"`
+}
+
+func TestUnmarshalDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		input  string
+		output string
+	}{
+		{"empty object", Config{}, `{}`,
+			`{"host":"localhost","port":8080,"debug":true,"ratio":0.5,"tags":["a","b"],"db":{"name":"app","pool":10},"replica":null,"plain":0}`},
+		{"explicit zero values", Config{}, `{"host":"","port":0,"debug":false,"tags":[],"db":{"pool":0}}`,
+			`{"host":"","port":0,"debug":false,"ratio":0.5,"tags":[],"db":{"name":"app","pool":0},"replica":null,"plain":0}`},
+		{"null keeps the zero value", Config{}, `{"port":null,"db":null}`,
+			`{"host":"localhost","port":0,"debug":true,"ratio":0.5,"tags":["a","b"],"db":{"name":"","pool":0},"replica":null,"plain":0}`},
+		{"null with DefaultsOnNull", Config{DefaultsOnNull: true}, `{"port":null,"ratio":null}`,
+			`{"host":"localhost","port":8080,"debug":true,"ratio":0.5,"tags":["a","b"],"db":{"name":"app","pool":10},"replica":null,"plain":0}`},
+		{"nested pointer", Config{}, `{"replica":{"name":"r"}}`,
+			`{"host":"localhost","port":8080,"debug":true,"ratio":0.5,"tags":["a","b"],"db":{"name":"app","pool":10},"replica":{"name":"r","pool":10},"plain":0}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := tt.config.Froze()
+			var c defaultsConfig
+			if err := api.UnmarshalFromString(tt.input, &c); err != nil {
+				t.Fatal(err)
+			}
+			output, _ := MarshalToString(c)
+			if output != tt.output {
+				t.Fatalf("expected %s, got %s", tt.output, output)
+			}
+		})
+	}
+}
+
+func TestFillDefaults(t *testing.T) {
+	c := defaultsConfig{Port: 1, Replica: &defaultsInner{Pool: 2}}
+	if err := FillDefaults(&c); err != nil {
+		t.Fatal(err)
+	}
+	output, _ := MarshalToString(c)
+	expected := `{"host":"localhost","port":1,"debug":true,"ratio":0.5,"tags":["a","b"],"db":{"name":"app","pool":10},"replica":{"name":"app","pool":2},"plain":0}`
+	if output != expected {
+		t.Fatalf("expected %s, got %s", expected, output)
+	}
+	if err := FillDefaults(c); err == nil {
+		t.Fatal("expected an error for a non pointer")
+	}
+	if err := FillDefaults((*defaultsConfig)(nil)); err == nil {
+		t.Fatal("expected an error for a nil pointer")
+	}
+	n := 1
+	if err := FillDefaults(&n); err != nil || n != 1 {
+		t.Fatal("not a struct")
+	}
+	var invalid struct {
+		Port int `default:"x"`
+	}
+	if err := FillDefaults(&invalid); err == nil || !strings.Contains(err.Error(), "Port") {
+		t.Fatalf("expected an error for an invalid default, got %v", err)
+	}
+	if err := UnmarshalFromString(`{}`, &invalid); err == nil {
+		t.Fatal("expected Unmarshal to report an invalid default")
+	}
+}
@@ -0,0 +1,82 @@
+package jsoniter
+
+import (
+	"strings"
+
+	"github.com/zhangdapeng520/zdpgo_json/reflect2"
+)
+
+// DeprecatedField is passed to Iterator.OnDeprecated when a struct field
+// tagged with deprecated, such as `json:"fullName,deprecated"`, is decoded
+type DeprecatedField struct {
+	Type  reflect2.Type // the struct
+	Field string        // the name of the struct field
+	Key   string        // the key of the input, the name of the field or an alias
+}
+
+// tagAliases returns the names of an alias=fullName|full_name tag option
+func tagAliases(tagParts []string) []string {
+	var aliases []string
+	for _, tagPart := range tagParts {
+		if strings.HasPrefix(tagPart, "alias=") {
+			for _, alias := range strings.Split(tagPart[len("alias="):], "|") {
+				if alias != "" {
+					aliases = append(aliases, alias)
+				}
+			}
+		}
+	}
+	return aliases
+}
+
+// addAliases lets the binding decode from its aliases too, unless the field
+// is not decoded at all
+func addAliases(binding *Binding, aliases []string) {
+	if len(binding.FromNames) == 0 {
+		return
+	}
+	fromNames := binding.FromNames[:len(binding.FromNames):len(binding.FromNames)]
+	for _, alias := range aliases {
+		found := false
+		for _, fromName := range fromNames {
+			if fromName == alias {
+				found = true
+			}
+		}
+		if !found {
+			fromNames = append(fromNames, alias)
+		}
+	}
+	binding.FromNames = fromNames
+}
+
+// deprecatedFields returns the deprecated fields of a struct by decoder,
+// bindings is the result of decoderOfStruct
+func deprecatedFields(ctx *ctx, typ reflect2.Type, bindings map[string]*Binding) map[*structFieldDecoder]DeprecatedField {
+	var deprecated map[*structFieldDecoder]DeprecatedField
+	for _, binding := range bindings {
+		tagParts := strings.Split(binding.Field.Tag().Get(ctx.getTagKey()), ",")
+		for _, tagPart := range tagParts[1:] {
+			if tagPart != "deprecated" {
+				continue
+			}
+			if deprecated == nil {
+				deprecated = map[*structFieldDecoder]DeprecatedField{}
+			}
+			deprecated[binding.Decoder.(*structFieldDecoder)] = DeprecatedField{
+				Type:  typ,
+				Field: binding.Field.Name(),
+			}
+		}
+	}
+	return deprecated
+}
+
+// reportDeprecated calls OnDeprecated, if it is set, for the key of the field
+func (iter *Iterator) reportDeprecated(field DeprecatedField, key string) {
+	if iter.OnDeprecated == nil {
+		return
+	}
+	field.Key = key
+	iter.OnDeprecated(iter, field)
+}
@@ -0,0 +1,91 @@
+package jsoniter
+
+import (
+	"strings"
+	"testing"
+)
+
+type aliasedUser struct {
+	Name  string `json:"name,alias=fullName|full_name"`
+	Email string `json:"email,deprecated,alias=mail"`
+	Age   int    `json:"age,omitempty"`
+	Skip  string `json:"-"`
+}
+
+func TestFieldAliases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected aliasedUser
+	}{
+		{`{"name":"a"}`, aliasedUser{Name: "a"}},
+		{`{"fullName":"b"}`, aliasedUser{Name: "b"}},
+		{`{"full_name":"c","age":1}`, aliasedUser{Name: "c", Age: 1}},
+		{`{"FULLNAME":"d"}`, aliasedUser{Name: "d"}},
+		{`{"mail":"e"}`, aliasedUser{Email: "e"}},
+		{`{"name":"a","fullName":"b"}`, aliasedUser{Name: "b"}},
+		{`{"Skip":"x"}`, aliasedUser{}},
+	}
+	for _, tt := range tests {
+		var user aliasedUser
+		if err := UnmarshalFromString(tt.input, &user); err != nil || user != tt.expected {
+			t.Fatalf("%s: expected %+v, got %+v %v", tt.input, tt.expected, user, err)
+		}
+	}
+	// the aliases are not used to encode
+	output, _ := MarshalToString(aliasedUser{Name: "a", Email: "b"})
+	if output != `{"name":"a","email":"b"}` {
+		t.Fatal(output)
+	}
+	// an alias of a field is a duplicate of it
+	api := Config{DuplicateKeys: DuplicateKeysError}.Froze()
+	var user aliasedUser
+	if err := api.UnmarshalFromString(`{"name":"a","fullName":"b"}`, &user); err == nil {
+		t.Fatal("expected a duplicate key error")
+	}
+	caseSensitive := Config{CaseSensitive: true}.Froze()
+	user = aliasedUser{}
+	if err := caseSensitive.UnmarshalFromString(`{"FULLNAME":"d","full_name":"e"}`, &user); err != nil || user.Name != "e" {
+		t.Fatalf("case sensitive %+v %v", user, err)
+	}
+}
+
+func TestOnDeprecated(t *testing.T) {
+	var reported []string
+	decoder := NewDecoder(strings.NewReader(`{"name":"a","email":"b"} {"mail":"c"} {"age":1}`))
+	decoder.OnDeprecated(func(iter *Iterator, field DeprecatedField) {
+		reported = append(reported, field.Type.String()+"."+field.Field+":"+field.Key)
+	})
+	var users []aliasedUser
+	for decoder.More() {
+		var user aliasedUser
+		if err := decoder.Decode(&user); err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, user)
+	}
+	if len(users) != 3 || users[1].Email != "c" {
+		t.Fatalf("%+v", users)
+	}
+	expected := "jsoniter.aliasedUser.Email:email,jsoniter.aliasedUser.Email:mail"
+	if strings.Join(reported, ",") != expected {
+		t.Fatalf("expected %s, got %v", expected, reported)
+	}
+	// the iterators of Unmarshal do not report
+	var user aliasedUser
+	if err := UnmarshalFromString(`{"email":"b"}`, &user); err != nil || user.Email != "b" {
+		t.Fatal(err)
+	}
+	iter := ParseString(ConfigDefault, `{"email":"b","nested":{"email":1}}`)
+	count := 0
+	iter.OnDeprecated = func(iter *Iterator, field DeprecatedField) {
+		count++
+	}
+	var withNested struct {
+		aliasedUser
+		Nested map[string]int `json:"nested"`
+	}
+	iter.ReadVal(&withNested)
+	if iter.Error != nil || count != 1 || withNested.Email != "b" {
+		t.Fatalf("%d %+v %v", count, withNested, iter.Error)
+	}
+}
@@ -344,6 +344,9 @@ func describeStruct(ctx *ctx, typ reflect2.Type) *StructDescriptor {
 		if tag == "-" || field.Name() == "_" {
 			continue
 		}
+		if !ctx.inView(field) {
+			continue
+		}
 		tagParts := strings.Split(tag, ",")
 		if field.Anonymous() && (tag == "" || tagParts[0] == "") {
 			if field.Type().Kind() == reflect.Struct {
@@ -457,6 +460,7 @@ func processTags(structDescriptor *StructDescriptor, cfg *frozenConfig) {
 				}
 			}
 		}
+		addAliases(binding, tagAliases(tagParts[1:]))
 		binding.Decoder = &structFieldDecoder{binding.Field, binding.Decoder}
 		binding.Encoder = &structFieldEncoder{binding.Field, binding.Encoder, shouldOmitEmpty}
 	}
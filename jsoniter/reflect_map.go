@@ -168,33 +168,49 @@ func (decoder *mapDecoder) Decode(ptr unsafe.Pointer, iter *Iterator) {
 		return
 	}
 	iter.unreadByte()
-	key := decoder.keyType.UnsafeNew()
-	decoder.keyDecoder.Decode(key, iter)
-	c = iter.nextToken()
-	if c != ':' {
-		iter.ReportError("ReadMapCB", "expect : after object field, but found "+string([]byte{c}))
+	seen := iter.newKeySet()
+	if !decoder.decodeEntry(ptr, iter, seen) {
 		return
 	}
-	elem := decoder.elemType.UnsafeNew()
-	decoder.elemDecoder.Decode(elem, iter)
-	decoder.mapType.UnsafeSetIndex(ptr, key, elem)
+	keys := 1
 	for c = iter.nextToken(); c == ','; c = iter.nextToken() {
-		key := decoder.keyType.UnsafeNew()
-		decoder.keyDecoder.Decode(key, iter)
-		c = iter.nextToken()
-		if c != ':' {
-			iter.ReportError("ReadMapCB", "expect : after object field, but found "+string([]byte{c}))
+		keys++
+		if !iter.checkObjectKeys(keys) || !decoder.decodeEntry(ptr, iter, seen) {
 			return
 		}
-		elem := decoder.elemType.UnsafeNew()
-		decoder.elemDecoder.Decode(elem, iter)
-		decoder.mapType.UnsafeSetIndex(ptr, key, elem)
 	}
 	if c != '}' {
 		iter.ReportError("ReadMapCB", `expect }, but found `+string([]byte{c}))
 	}
 }
 
+// decodeEntry decodes one key and its value into the map, duplicate keys
+// are handled as the DuplicateKeys policy says
+func (decoder *mapDecoder) decodeEntry(ptr unsafe.Pointer, iter *Iterator, seen keySet) bool {
+	key := decoder.keyType.UnsafeNew()
+	decoder.keyDecoder.Decode(key, iter)
+	c := iter.nextToken()
+	if c != ':' {
+		iter.ReportError("ReadMapCB", "expect : after object field, but found "+string([]byte{c}))
+		return false
+	}
+	name := ""
+	if seen != nil {
+		id := decoder.keyType.UnsafeIndirect(key)
+		name = fmt.Sprint(id)
+		if !iter.acceptKey(seen, id, name) {
+			iter.Skip()
+			return iter.Error == nil
+		}
+	}
+	elem := decoder.elemType.UnsafeNew()
+	iter.pushKey(seen, name)
+	decoder.elemDecoder.Decode(elem, iter)
+	iter.popKey(seen)
+	decoder.mapType.UnsafeSetIndex(ptr, key, elem)
+	return true
+}
+
 type numericMapKeyDecoder struct {
 	decoder ValDecoder
 }
@@ -253,20 +269,28 @@ func (encoder *mapEncoder) Encode(ptr unsafe.Pointer, stream *Stream) {
 		stream.WriteNil()
 		return
 	}
+	fields := stream.fields
 	stream.WriteObjectStart()
 	iter := encoder.mapType.UnsafeIterate(ptr)
-	for i := 0; iter.HasNext(); i++ {
+	for i := 0; iter.HasNext(); {
+		key, elem := iter.UnsafeNext()
+		selected, found := stream.selectMapKey(encoder.keyEncoder, key)
+		if !found {
+			continue
+		}
 		if i != 0 {
 			stream.WriteMore()
 		}
-		key, elem := iter.UnsafeNext()
+		i++
 		encoder.keyEncoder.Encode(key, stream)
 		if stream.indention > 0 {
 			stream.writeTwoBytes(byte(':'), byte(' '))
 		} else {
 			stream.writeByte(':')
 		}
+		stream.fields = selected
 		encoder.elemEncoder.Encode(elem, stream)
+		stream.fields = fields
 	}
 	stream.WriteObjectEnd()
 }
@@ -291,6 +315,7 @@ func (encoder *sortKeysMapEncoder) Encode(ptr unsafe.Pointer, stream *Stream) {
 	mapIter := encoder.mapType.UnsafeIterate(ptr)
 	subStream := stream.cfg.BorrowStream(nil)
 	subStream.Attachment = stream.Attachment
+	subStream.view = stream.view
 	subIter := stream.cfg.BorrowIterator(nil)
 	keyValues := encodedKeyValues{}
 	for mapIter.HasNext() {
@@ -303,6 +328,12 @@ func (encoder *sortKeysMapEncoder) Encode(ptr unsafe.Pointer, stream *Stream) {
 		encodedKey := subStream.Buffer()[subStreamIndex:]
 		subIter.ResetBytes(encodedKey)
 		decodedKey := subIter.ReadString()
+		selected, found := stream.selectField(decodedKey)
+		if !found {
+			subStream.SetBuffer(subStream.Buffer()[:subStreamIndex])
+			continue
+		}
+		subStream.fields = selected
 		if stream.indention > 0 {
 			subStream.writeTwoBytes(byte(':'), byte(' '))
 		} else {
@@ -0,0 +1,148 @@
+package jsoniter
+
+import (
+	"unsafe"
+
+	"github.com/zhangdapeng520/zdpgo_json/reflect2"
+)
+
+// OrderedMap is a json object that keeps its keys in insertion order.
+// Unmarshal keeps the order of the document, nested objects become
+// *OrderedMap too, and Marshal writes the keys in the same order, even
+// when SortMapKeys is set.
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedMap returns an empty OrderedMap
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{values: map[string]interface{}{}}
+}
+
+// Set sets the value of the key, a new key is added after the others
+// while an existing key keeps its position
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if m.values == nil {
+		m.values = map[string]interface{}{}
+	}
+	if _, found := m.values[key]; !found {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get returns the value of the key
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, found := m.values[key]
+	return value, found
+}
+
+// Delete removes the key
+func (m *OrderedMap) Delete(key string) {
+	if _, found := m.values[key]; !found {
+		return
+	}
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the keys in order
+func (m *OrderedMap) Keys() []string {
+	return append([]string{}, m.keys...)
+}
+
+// Len returns the number of keys
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+var orderedMapType = reflect2.TypeOfPtr((*OrderedMap)(nil)).Elem()
+
+func createDecoderOfOrderedMap(ctx *ctx, typ reflect2.Type) ValDecoder {
+	if typ == orderedMapType {
+		return &orderedMapCodec{}
+	}
+	return nil
+}
+
+func createEncoderOfOrderedMap(ctx *ctx, typ reflect2.Type) ValEncoder {
+	if typ == orderedMapType {
+		return &orderedMapCodec{}
+	}
+	return nil
+}
+
+type orderedMapCodec struct {
+}
+
+func (codec *orderedMapCodec) Decode(ptr unsafe.Pointer, iter *Iterator) {
+	m := (*OrderedMap)(ptr)
+	if iter.ReadNil() {
+		*m = OrderedMap{}
+		return
+	}
+	*m = OrderedMap{values: map[string]interface{}{}}
+	iter.readOrderedMap(m)
+}
+
+func (codec *orderedMapCodec) Encode(ptr unsafe.Pointer, stream *Stream) {
+	m := (*OrderedMap)(ptr)
+	if len(m.keys) == 0 {
+		stream.WriteEmptyObject()
+		return
+	}
+	fields := stream.fields
+	stream.WriteObjectStart()
+	isNotFirst := false
+	for _, key := range m.keys {
+		selected, found := stream.selectField(key)
+		if !found {
+			continue
+		}
+		if isNotFirst {
+			stream.WriteMore()
+		}
+		stream.WriteObjectField(key)
+		stream.fields = selected
+		stream.WriteVal(m.values[key])
+		stream.fields = fields
+		isNotFirst = true
+	}
+	stream.WriteObjectEnd()
+}
+
+func (codec *orderedMapCodec) IsEmpty(ptr unsafe.Pointer) bool {
+	return len((*OrderedMap)(ptr).keys) == 0
+}
+
+// readOrderedMap reads the members of an object into m
+func (iter *Iterator) readOrderedMap(m *OrderedMap) {
+	iter.ReadMapCB(func(iter *Iterator, field string) bool {
+		m.Set(field, iter.readOrdered())
+		return true
+	})
+}
+
+// readOrdered reads like Read, but objects become *OrderedMap
+func (iter *Iterator) readOrdered() interface{} {
+	switch iter.WhatIsNext() {
+	case ObjectValue:
+		m := NewOrderedMap()
+		iter.readOrderedMap(m)
+		return m
+	case ArrayValue:
+		arr := []interface{}{}
+		iter.ReadArrayCB(func(iter *Iterator) bool {
+			arr = append(arr, iter.readOrdered())
+			return true
+		})
+		return arr
+	}
+	return iter.Read()
+}
@@ -0,0 +1,108 @@
+package jsoniter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOrderedMapRoundTrip(t *testing.T) {
+	tests := []string{
+		`{}`,
+		`{"z":1,"a":2,"m":3}`,
+		`{"b":{"y":1,"x":[{"d":1,"c":2}]},"a":null}`,
+		`{"z":"s","y":true,"x":1.5,"w":[1,"a",{"k":{"j":[]}}]}`,
+	}
+	for _, cfg := range []API{ConfigDefault, ConfigCompatibleWithStandardLibrary} {
+		for _, input := range tests {
+			var m OrderedMap
+			if err := cfg.UnmarshalFromString(input, &m); err != nil {
+				t.Fatalf("%s: %v", input, err)
+			}
+			output, err := cfg.MarshalToString(&m)
+			if err != nil || output != input {
+				t.Fatalf("expected %s, got %s %v", input, output, err)
+			}
+		}
+	}
+}
+
+func TestOrderedMapNested(t *testing.T) {
+	var m OrderedMap
+	if err := UnmarshalFromString(`{"b":{"y":1,"x":2},"a":[{"d":1,"c":2}]}`, &m); err != nil {
+		t.Fatal(err)
+	}
+	if keys := strings.Join(m.Keys(), ","); keys != "b,a" {
+		t.Fatalf("keys %s", keys)
+	}
+	b, _ := m.Get("b")
+	nested, ok := b.(*OrderedMap)
+	if !ok || strings.Join(nested.Keys(), ",") != "y,x" {
+		t.Fatalf("nested object %#v", b)
+	}
+	a, _ := m.Get("a")
+	elems, ok := a.([]interface{})
+	if !ok || len(elems) != 1 {
+		t.Fatalf("nested array %#v", a)
+	}
+	if elem, ok := elems[0].(*OrderedMap); !ok || strings.Join(elem.Keys(), ",") != "d,c" {
+		t.Fatalf("object in array %#v", elems[0])
+	}
+}
+
+func TestOrderedMapEdits(t *testing.T) {
+	m := NewOrderedMap()
+	m.Set("z", 1)
+	m.Set("a", NewOrderedMap())
+	m.Set("m", []int{1})
+	m.Set("z", 2)
+	m.Delete("a")
+	m.Delete("missing")
+	if m.Len() != 2 || strings.Join(m.Keys(), ",") != "z,m" {
+		t.Fatalf("keys %v", m.Keys())
+	}
+	output, err := MarshalToString(m)
+	if err != nil || output != `{"z":2,"m":[1]}` {
+		t.Fatalf("%s %v", output, err)
+	}
+	var zero OrderedMap
+	zero.Set("k", "v")
+	if output, _ := MarshalToString(zero); output != `{"k":"v"}` {
+		t.Fatalf("zero value %s", output)
+	}
+	var reset OrderedMap
+	reset.Set("k", "v")
+	if err := UnmarshalFromString(`null`, &reset); err != nil || reset.Len() != 0 {
+		t.Fatalf("null %v %v", reset.Keys(), err)
+	}
+}
+
+func TestUseOrderedMap(t *testing.T) {
+	input := `{"z":1,"a":{"y":true,"b":[{"q":1,"p":2}]}}`
+	decoder := NewDecoder(strings.NewReader(input))
+	decoder.UseOrderedMap()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := v.(*OrderedMap); !ok {
+		t.Fatalf("expected *OrderedMap, got %T", v)
+	}
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(v); err != nil {
+		t.Fatal(err)
+	}
+	if output := strings.TrimSpace(buf.String()); output != input {
+		t.Fatalf("expected %s, got %s", input, output)
+	}
+	var s struct {
+		Any interface{}
+	}
+	cfg := Config{UseOrderedMap: true, SortMapKeys: true}.Froze()
+	if err := cfg.UnmarshalFromString(`{"Any":{"b":1,"a":2}}`, &s); err != nil {
+		t.Fatal(err)
+	}
+	if output, _ := cfg.MarshalToString(s); output != `{"Any":{"b":1,"a":2}}` {
+		t.Fatalf("SortMapKeys should not reorder an OrderedMap: %s", output)
+	}
+}
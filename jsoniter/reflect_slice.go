@@ -59,7 +59,7 @@ type sliceDecoder struct {
 func (decoder *sliceDecoder) Decode(ptr unsafe.Pointer, iter *Iterator) {
 	decoder.doDecode(ptr, iter)
 	if iter.Error != nil && iter.Error != io.EOF {
-		iter.Error = fmt.Errorf("%v: %s", decoder.sliceType, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v: %w", decoder.sliceType, iter.Error)
 	}
 }
 
@@ -83,14 +83,21 @@ func (decoder *sliceDecoder) doDecode(ptr unsafe.Pointer, iter *Iterator) {
 	iter.unreadByte()
 	sliceType.UnsafeGrow(ptr, 1)
 	elemPtr := sliceType.UnsafeGetIndex(ptr, 0)
+	iter.pushIndex(0)
 	decoder.elemDecoder.Decode(elemPtr, iter)
+	iter.popIndex()
 	length := 1
 	for c = iter.nextToken(); c == ','; c = iter.nextToken() {
 		idx := length
 		length += 1
+		if !iter.checkArrayElements(length) {
+			return
+		}
 		sliceType.UnsafeGrow(ptr, length)
 		elemPtr = sliceType.UnsafeGetIndex(ptr, idx)
+		iter.pushIndex(idx)
 		decoder.elemDecoder.Decode(elemPtr, iter)
+		iter.popIndex()
 	}
 	if c != ']' {
 		iter.ReportError("decode slice", "expect ], but found "+string([]byte{c}))
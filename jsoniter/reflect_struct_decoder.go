@@ -12,7 +12,11 @@ import (
 func decoderOfStruct(ctx *ctx, typ reflect2.Type) ValDecoder {
 	bindings := map[string]*Binding{}
 	structDescriptor := describeStruct(ctx, typ)
+	unknownBinding := unknownFieldsBinding(ctx, structDescriptor)
 	for _, binding := range structDescriptor.Fields {
+		if binding == unknownBinding {
+			continue
+		}
 		for _, fromName := range binding.FromNames {
 			old := bindings[fromName]
 			if old == nil {
@@ -41,12 +45,25 @@ func decoderOfStruct(ctx *ctx, typ reflect2.Type) ValDecoder {
 		}
 	}
 
+	// only the general decoder knows the keys to report deprecated fields,
+	// to keep the unknown ones and to fill the defaults of the missing ones
+	deprecated := deprecatedFields(ctx, typ, bindings)
+	defaults := defaultsOfStruct(typ, bindings)
+	if deprecated != nil || unknownBinding != nil || defaults != nil {
+		decoder := &generalStructDecoder{typ: typ, fields: fields,
+			disallowUnknownFields: ctx.disallowUnknownFields, deprecated: deprecated,
+			defaults: defaults}
+		if unknownBinding != nil {
+			decoder.unknown = decoderOfUnknownFields(ctx, unknownBinding)
+		}
+		return decoder
+	}
 	return createStructDecoder(ctx, typ, fields)
 }
 
 func createStructDecoder(ctx *ctx, typ reflect2.Type, fields map[string]*structFieldDecoder) ValDecoder {
-	if ctx.disallowUnknownFields {
-		return &generalStructDecoder{typ: typ, fields: fields, disallowUnknownFields: true}
+	if ctx.disallowUnknownFields || ctx.duplicateKeys != DuplicateKeysAllowLast {
+		return &generalStructDecoder{typ: typ, fields: fields, disallowUnknownFields: ctx.disallowUnknownFields}
 	}
 	knownHash := map[int64]struct{}{
 		0: {},
@@ -60,7 +77,7 @@ func createStructDecoder(ctx *ctx, typ reflect2.Type, fields map[string]*structF
 			fieldHash := calcHash(fieldName, ctx.caseSensitive())
 			_, known := knownHash[fieldHash]
 			if known {
-				return &generalStructDecoder{typ, fields, false}
+				return &generalStructDecoder{typ, fields, false, nil, nil, nil}
 			}
 			knownHash[fieldHash] = struct{}{}
 			return &oneFieldStructDecoder{typ, fieldHash, fieldDecoder}
@@ -74,7 +91,7 @@ func createStructDecoder(ctx *ctx, typ reflect2.Type, fields map[string]*structF
 			fieldHash := calcHash(fieldName, ctx.caseSensitive())
 			_, known := knownHash[fieldHash]
 			if known {
-				return &generalStructDecoder{typ, fields, false}
+				return &generalStructDecoder{typ, fields, false, nil, nil, nil}
 			}
 			knownHash[fieldHash] = struct{}{}
 			if fieldHash1 == 0 {
@@ -97,7 +114,7 @@ func createStructDecoder(ctx *ctx, typ reflect2.Type, fields map[string]*structF
 			fieldHash := calcHash(fieldName, ctx.caseSensitive())
 			_, known := knownHash[fieldHash]
 			if known {
-				return &generalStructDecoder{typ, fields, false}
+				return &generalStructDecoder{typ, fields, false, nil, nil, nil}
 			}
 			knownHash[fieldHash] = struct{}{}
 			if fieldName1 == 0 {
@@ -128,7 +145,7 @@ func createStructDecoder(ctx *ctx, typ reflect2.Type, fields map[string]*structF
 			fieldHash := calcHash(fieldName, ctx.caseSensitive())
 			_, known := knownHash[fieldHash]
 			if known {
-				return &generalStructDecoder{typ, fields, false}
+				return &generalStructDecoder{typ, fields, false, nil, nil, nil}
 			}
 			knownHash[fieldHash] = struct{}{}
 			if fieldName1 == 0 {
@@ -165,7 +182,7 @@ func createStructDecoder(ctx *ctx, typ reflect2.Type, fields map[string]*structF
 			fieldHash := calcHash(fieldName, ctx.caseSensitive())
 			_, known := knownHash[fieldHash]
 			if known {
-				return &generalStructDecoder{typ, fields, false}
+				return &generalStructDecoder{typ, fields, false, nil, nil, nil}
 			}
 			knownHash[fieldHash] = struct{}{}
 			if fieldName1 == 0 {
@@ -208,7 +225,7 @@ func createStructDecoder(ctx *ctx, typ reflect2.Type, fields map[string]*structF
 			fieldHash := calcHash(fieldName, ctx.caseSensitive())
 			_, known := knownHash[fieldHash]
 			if known {
-				return &generalStructDecoder{typ, fields, false}
+				return &generalStructDecoder{typ, fields, false, nil, nil, nil}
 			}
 			knownHash[fieldHash] = struct{}{}
 			if fieldName1 == 0 {
@@ -257,7 +274,7 @@ func createStructDecoder(ctx *ctx, typ reflect2.Type, fields map[string]*structF
 			fieldHash := calcHash(fieldName, ctx.caseSensitive())
 			_, known := knownHash[fieldHash]
 			if known {
-				return &generalStructDecoder{typ, fields, false}
+				return &generalStructDecoder{typ, fields, false, nil, nil, nil}
 			}
 			knownHash[fieldHash] = struct{}{}
 			if fieldName1 == 0 {
@@ -312,7 +329,7 @@ func createStructDecoder(ctx *ctx, typ reflect2.Type, fields map[string]*structF
 			fieldHash := calcHash(fieldName, ctx.caseSensitive())
 			_, known := knownHash[fieldHash]
 			if known {
-				return &generalStructDecoder{typ, fields, false}
+				return &generalStructDecoder{typ, fields, false, nil, nil, nil}
 			}
 			knownHash[fieldHash] = struct{}{}
 			if fieldName1 == 0 {
@@ -373,7 +390,7 @@ func createStructDecoder(ctx *ctx, typ reflect2.Type, fields map[string]*structF
 			fieldHash := calcHash(fieldName, ctx.caseSensitive())
 			_, known := knownHash[fieldHash]
 			if known {
-				return &generalStructDecoder{typ, fields, false}
+				return &generalStructDecoder{typ, fields, false, nil, nil, nil}
 			}
 			knownHash[fieldHash] = struct{}{}
 			if fieldName1 == 0 {
@@ -440,7 +457,7 @@ func createStructDecoder(ctx *ctx, typ reflect2.Type, fields map[string]*structF
 			fieldHash := calcHash(fieldName, ctx.caseSensitive())
 			_, known := knownHash[fieldHash]
 			if known {
-				return &generalStructDecoder{typ, fields, false}
+				return &generalStructDecoder{typ, fields, false, nil, nil, nil}
 			}
 			knownHash[fieldHash] = struct{}{}
 			if fieldName1 == 0 {
@@ -487,28 +504,52 @@ func createStructDecoder(ctx *ctx, typ reflect2.Type, fields map[string]*structF
 			fieldName9, fieldDecoder9,
 			fieldName10, fieldDecoder10}
 	}
-	return &generalStructDecoder{typ, fields, false}
+	return &generalStructDecoder{typ, fields, false, nil, nil, nil}
 }
 
 type generalStructDecoder struct {
 	typ                   reflect2.Type
 	fields                map[string]*structFieldDecoder
 	disallowUnknownFields bool
+	deprecated            map[*structFieldDecoder]DeprecatedField
+	unknown               *unknownFields
+	defaults              *structDefaults
 }
 
 func (decoder *generalStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterator) {
+	// an empty object has every key missing, unlike null
+	object := decoder.defaults != nil && iter.WhatIsNext() == ObjectValue
 	if !iter.readObjectStart() {
+		if object && iter.Error == nil {
+			decoder.defaults.fill(ptr, iter, make([]bool, len(decoder.defaults.entries)))
+		}
 		return
 	}
 	if !iter.incrementDepth() {
 		return
 	}
 	var c byte
+	keys := 0
+	seen := iter.newKeySet()
+	var decoded []bool
+	if decoder.defaults != nil {
+		decoded = make([]bool, len(decoder.defaults.entries))
+	}
 	for c = ','; c == ','; c = iter.nextToken() {
-		decoder.decodeOneField(ptr, iter)
+		keys++
+		if !iter.checkObjectKeys(keys) {
+			break
+		}
+		fieldDecoder := decoder.decodeOneField(ptr, iter, seen)
+		if i, found := decoder.defaults.indexOf(fieldDecoder); found {
+			decoded[i] = true
+		}
+	}
+	if decoder.defaults != nil && c == '}' && iter.Error == nil {
+		decoder.defaults.fill(ptr, iter, decoded)
 	}
 	if iter.Error != nil && iter.Error != io.EOF && len(decoder.typ.Type1().Name()) != 0 {
-		iter.Error = fmt.Errorf("%v.%s", decoder.typ, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v.%w", decoder.typ, iter.Error)
 	}
 	if c != '}' {
 		iter.ReportError("struct Decode", `expect }, but found `+string([]byte{c}))
@@ -516,7 +557,7 @@ func (decoder *generalStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterator)
 	iter.decrementDepth()
 }
 
-func (decoder *generalStructDecoder) decodeOneField(ptr unsafe.Pointer, iter *Iterator) {
+func (decoder *generalStructDecoder) decodeOneField(ptr unsafe.Pointer, iter *Iterator, seen keySet) *structFieldDecoder {
 	var field string
 	var fieldDecoder *structFieldDecoder
 	if iter.cfg.objectFieldMustBeSimpleString {
@@ -533,6 +574,33 @@ func (decoder *generalStructDecoder) decodeOneField(ptr unsafe.Pointer, iter *It
 			fieldDecoder = decoder.fields[strings.ToLower(field)]
 		}
 	}
+	if seen != nil {
+		// the fields that differ only in case are the same key when the
+		// config is not case sensitive
+		var id interface{} = field
+		if fieldDecoder != nil {
+			id = fieldDecoder
+		}
+		if !iter.acceptKey(seen, id, field) {
+			c := iter.nextToken()
+			if c != ':' {
+				iter.ReportError("ReadObject", "expect : after object field, but found "+string([]byte{c}))
+			}
+			iter.Skip()
+			return nil
+		}
+	}
+	if fieldDecoder == nil && decoder.unknown != nil {
+		c := iter.nextToken()
+		if c != ':' {
+			iter.ReportError("ReadObject", "expect : after object field, but found "+string([]byte{c}))
+		}
+		iter.pushKey(seen, field)
+		// the field may share the buffer of the iterator
+		decoder.unknown.decode(ptr, string(append([]byte(nil), field...)), iter)
+		iter.popKey(seen)
+		return nil
+	}
 	if fieldDecoder == nil {
 		if decoder.disallowUnknownFields {
 			msg := "found unknown field: " + field
@@ -543,13 +611,24 @@ func (decoder *generalStructDecoder) decodeOneField(ptr unsafe.Pointer, iter *It
 			iter.ReportError("ReadObject", "expect : after object field, but found "+string([]byte{c}))
 		}
 		iter.Skip()
-		return
+		return nil
 	}
 	c := iter.nextToken()
 	if c != ':' {
 		iter.ReportError("ReadObject", "expect : after object field, but found "+string([]byte{c}))
 	}
+	if deprecated, found := decoder.deprecated[fieldDecoder]; found {
+		iter.reportDeprecated(deprecated, field)
+	}
+	if _, found := decoder.defaults.indexOf(fieldDecoder); found &&
+		iter.cfg.defaultsOnNull && iter.ReadNil() {
+		// null is a missing key for the defaults
+		return nil
+	}
+	iter.pushKey(seen, field)
 	fieldDecoder.Decode(ptr, iter)
+	iter.popKey(seen)
+	return fieldDecoder
 }
 
 type skipObjectDecoder struct {
@@ -578,7 +657,7 @@ func (decoder *oneFieldStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterator)
 	if !iter.incrementDepth() {
 		return
 	}
-	for {
+	for keys := 1; iter.checkObjectKeys(keys); keys++ {
 		if iter.readFieldHash() == decoder.fieldHash {
 			decoder.fieldDecoder.Decode(ptr, iter)
 		} else {
@@ -589,7 +668,7 @@ func (decoder *oneFieldStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterator)
 		}
 	}
 	if iter.Error != nil && iter.Error != io.EOF && len(decoder.typ.Type1().Name()) != 0 {
-		iter.Error = fmt.Errorf("%v.%s", decoder.typ, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v.%w", decoder.typ, iter.Error)
 	}
 	iter.decrementDepth()
 }
@@ -609,7 +688,7 @@ func (decoder *twoFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterator
 	if !iter.incrementDepth() {
 		return
 	}
-	for {
+	for keys := 1; iter.checkObjectKeys(keys); keys++ {
 		switch iter.readFieldHash() {
 		case decoder.fieldHash1:
 			decoder.fieldDecoder1.Decode(ptr, iter)
@@ -623,7 +702,7 @@ func (decoder *twoFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterator
 		}
 	}
 	if iter.Error != nil && iter.Error != io.EOF && len(decoder.typ.Type1().Name()) != 0 {
-		iter.Error = fmt.Errorf("%v.%s", decoder.typ, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v.%w", decoder.typ, iter.Error)
 	}
 	iter.decrementDepth()
 }
@@ -645,7 +724,7 @@ func (decoder *threeFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterat
 	if !iter.incrementDepth() {
 		return
 	}
-	for {
+	for keys := 1; iter.checkObjectKeys(keys); keys++ {
 		switch iter.readFieldHash() {
 		case decoder.fieldHash1:
 			decoder.fieldDecoder1.Decode(ptr, iter)
@@ -661,7 +740,7 @@ func (decoder *threeFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterat
 		}
 	}
 	if iter.Error != nil && iter.Error != io.EOF && len(decoder.typ.Type1().Name()) != 0 {
-		iter.Error = fmt.Errorf("%v.%s", decoder.typ, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v.%w", decoder.typ, iter.Error)
 	}
 	iter.decrementDepth()
 }
@@ -685,7 +764,7 @@ func (decoder *fourFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterato
 	if !iter.incrementDepth() {
 		return
 	}
-	for {
+	for keys := 1; iter.checkObjectKeys(keys); keys++ {
 		switch iter.readFieldHash() {
 		case decoder.fieldHash1:
 			decoder.fieldDecoder1.Decode(ptr, iter)
@@ -703,7 +782,7 @@ func (decoder *fourFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterato
 		}
 	}
 	if iter.Error != nil && iter.Error != io.EOF && len(decoder.typ.Type1().Name()) != 0 {
-		iter.Error = fmt.Errorf("%v.%s", decoder.typ, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v.%w", decoder.typ, iter.Error)
 	}
 	iter.decrementDepth()
 }
@@ -729,7 +808,7 @@ func (decoder *fiveFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterato
 	if !iter.incrementDepth() {
 		return
 	}
-	for {
+	for keys := 1; iter.checkObjectKeys(keys); keys++ {
 		switch iter.readFieldHash() {
 		case decoder.fieldHash1:
 			decoder.fieldDecoder1.Decode(ptr, iter)
@@ -749,7 +828,7 @@ func (decoder *fiveFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterato
 		}
 	}
 	if iter.Error != nil && iter.Error != io.EOF && len(decoder.typ.Type1().Name()) != 0 {
-		iter.Error = fmt.Errorf("%v.%s", decoder.typ, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v.%w", decoder.typ, iter.Error)
 	}
 	iter.decrementDepth()
 }
@@ -777,7 +856,7 @@ func (decoder *sixFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterator
 	if !iter.incrementDepth() {
 		return
 	}
-	for {
+	for keys := 1; iter.checkObjectKeys(keys); keys++ {
 		switch iter.readFieldHash() {
 		case decoder.fieldHash1:
 			decoder.fieldDecoder1.Decode(ptr, iter)
@@ -799,7 +878,7 @@ func (decoder *sixFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterator
 		}
 	}
 	if iter.Error != nil && iter.Error != io.EOF && len(decoder.typ.Type1().Name()) != 0 {
-		iter.Error = fmt.Errorf("%v.%s", decoder.typ, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v.%w", decoder.typ, iter.Error)
 	}
 	iter.decrementDepth()
 }
@@ -829,7 +908,7 @@ func (decoder *sevenFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterat
 	if !iter.incrementDepth() {
 		return
 	}
-	for {
+	for keys := 1; iter.checkObjectKeys(keys); keys++ {
 		switch iter.readFieldHash() {
 		case decoder.fieldHash1:
 			decoder.fieldDecoder1.Decode(ptr, iter)
@@ -853,7 +932,7 @@ func (decoder *sevenFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterat
 		}
 	}
 	if iter.Error != nil && iter.Error != io.EOF && len(decoder.typ.Type1().Name()) != 0 {
-		iter.Error = fmt.Errorf("%v.%s", decoder.typ, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v.%w", decoder.typ, iter.Error)
 	}
 	iter.decrementDepth()
 }
@@ -885,7 +964,7 @@ func (decoder *eightFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterat
 	if !iter.incrementDepth() {
 		return
 	}
-	for {
+	for keys := 1; iter.checkObjectKeys(keys); keys++ {
 		switch iter.readFieldHash() {
 		case decoder.fieldHash1:
 			decoder.fieldDecoder1.Decode(ptr, iter)
@@ -911,7 +990,7 @@ func (decoder *eightFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterat
 		}
 	}
 	if iter.Error != nil && iter.Error != io.EOF && len(decoder.typ.Type1().Name()) != 0 {
-		iter.Error = fmt.Errorf("%v.%s", decoder.typ, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v.%w", decoder.typ, iter.Error)
 	}
 	iter.decrementDepth()
 }
@@ -945,7 +1024,7 @@ func (decoder *nineFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterato
 	if !iter.incrementDepth() {
 		return
 	}
-	for {
+	for keys := 1; iter.checkObjectKeys(keys); keys++ {
 		switch iter.readFieldHash() {
 		case decoder.fieldHash1:
 			decoder.fieldDecoder1.Decode(ptr, iter)
@@ -973,7 +1052,7 @@ func (decoder *nineFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterato
 		}
 	}
 	if iter.Error != nil && iter.Error != io.EOF && len(decoder.typ.Type1().Name()) != 0 {
-		iter.Error = fmt.Errorf("%v.%s", decoder.typ, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v.%w", decoder.typ, iter.Error)
 	}
 	iter.decrementDepth()
 }
@@ -1009,7 +1088,7 @@ func (decoder *tenFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterator
 	if !iter.incrementDepth() {
 		return
 	}
-	for {
+	for keys := 1; iter.checkObjectKeys(keys); keys++ {
 		switch iter.readFieldHash() {
 		case decoder.fieldHash1:
 			decoder.fieldDecoder1.Decode(ptr, iter)
@@ -1039,7 +1118,7 @@ func (decoder *tenFieldsStructDecoder) Decode(ptr unsafe.Pointer, iter *Iterator
 		}
 	}
 	if iter.Error != nil && iter.Error != io.EOF && len(decoder.typ.Type1().Name()) != 0 {
-		iter.Error = fmt.Errorf("%v.%s", decoder.typ, iter.Error.Error())
+		iter.Error = fmt.Errorf("%v.%w", decoder.typ, iter.Error)
 	}
 	iter.decrementDepth()
 }
@@ -1053,7 +1132,7 @@ func (decoder *structFieldDecoder) Decode(ptr unsafe.Pointer, iter *Iterator) {
 	fieldPtr := decoder.field.UnsafeGet(ptr)
 	decoder.fieldDecoder.Decode(fieldPtr, iter)
 	if iter.Error != nil && iter.Error != io.EOF {
-		iter.Error = fmt.Errorf("%s: %s", decoder.field.Name(), iter.Error.Error())
+		iter.Error = fmt.Errorf("%s: %w", decoder.field.Name(), iter.Error)
 	}
 }
 
@@ -16,7 +16,11 @@ func encoderOfStruct(ctx *ctx, typ reflect2.Type) ValEncoder {
 	}
 	orderedBindings := []*bindingTo{}
 	structDescriptor := describeStruct(ctx, typ)
+	unknownBinding := unknownFieldsBinding(ctx, structDescriptor)
 	for _, binding := range structDescriptor.Fields {
+		if binding == unknownBinding {
+			continue
+		}
 		for _, toName := range binding.ToNames {
 			new := &bindingTo{
 				binding: binding,
@@ -31,7 +35,7 @@ func encoderOfStruct(ctx *ctx, typ reflect2.Type) ValEncoder {
 			orderedBindings = append(orderedBindings, new)
 		}
 	}
-	if len(orderedBindings) == 0 {
+	if len(orderedBindings) == 0 && unknownBinding == nil {
 		return &emptyStructEncoder{}
 	}
 	finalOrderedFields := []structFieldTo{}
@@ -43,7 +47,11 @@ func encoderOfStruct(ctx *ctx, typ reflect2.Type) ValEncoder {
 			})
 		}
 	}
-	return &structEncoder{typ, finalOrderedFields}
+	encoder := &structEncoder{typ: typ, fields: finalOrderedFields}
+	if unknownBinding != nil {
+		encoder.unknown = encoderOfUnknownFields(ctx, unknownBinding)
+	}
+	return encoder
 }
 
 func createCheckIsEmpty(ctx *ctx, typ reflect2.Type) checkIsEmpty {
@@ -132,8 +140,9 @@ type IsEmbeddedPtrNil interface {
 }
 
 type structEncoder struct {
-	typ    reflect2.Type
-	fields []structFieldTo
+	typ     reflect2.Type
+	fields  []structFieldTo
+	unknown *unknownFields
 }
 
 type structFieldTo struct {
@@ -142,9 +151,14 @@ type structFieldTo struct {
 }
 
 func (encoder *structEncoder) Encode(ptr unsafe.Pointer, stream *Stream) {
+	fields := stream.fields
 	stream.WriteObjectStart()
 	isNotFirst := false
 	for _, field := range encoder.fields {
+		selected, found := stream.selectField(field.toName)
+		if !found {
+			continue
+		}
 		if field.encoder.omitempty && field.encoder.IsEmpty(ptr) {
 			continue
 		}
@@ -155,9 +169,14 @@ func (encoder *structEncoder) Encode(ptr unsafe.Pointer, stream *Stream) {
 			stream.WriteMore()
 		}
 		stream.WriteObjectField(field.toName)
+		stream.fields = selected
 		field.encoder.Encode(ptr, stream)
+		stream.fields = fields
 		isNotFirst = true
 	}
+	if encoder.unknown != nil {
+		encoder.unknown.encode(ptr, stream, encoder.fields, isNotFirst)
+	}
 	stream.WriteObjectEnd()
 	if stream.Error != nil && stream.Error != io.EOF {
 		stream.Error = fmt.Errorf("%v.%s", encoder.typ, stream.Error.Error())
@@ -0,0 +1,117 @@
+package jsoniter
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/zhangdapeng520/zdpgo_json/reflect2"
+)
+
+// unknownFields is the catch-all field of a struct, a map with string keys
+// tagged with inline or unknown, such as `json:",inline"`. It keeps the keys
+// that no other field decodes, and encodes them back among the fields.
+type unknownFields struct {
+	field       reflect2.StructField
+	mapType     *reflect2.UnsafeMapType
+	elemDecoder ValDecoder
+	elemEncoder ValEncoder
+}
+
+// unknownFieldsBinding returns the catch-all field of the struct, if there is
+// one. The field must be declared in the struct itself, not embedded.
+func unknownFieldsBinding(ctx *ctx, structDescriptor *StructDescriptor) *Binding {
+	for _, binding := range structDescriptor.Fields {
+		if len(binding.levels) != 1 || binding.Field.Type().Kind() != reflect.Map {
+			continue
+		}
+		if binding.Field.Type().(*reflect2.UnsafeMapType).Key().Kind() != reflect.String {
+			continue
+		}
+		tagParts := strings.Split(binding.Field.Tag().Get(ctx.getTagKey()), ",")
+		for _, tagPart := range tagParts[1:] {
+			if tagPart == "inline" || tagPart == "unknown" {
+				return binding
+			}
+		}
+	}
+	return nil
+}
+
+func decoderOfUnknownFields(ctx *ctx, binding *Binding) *unknownFields {
+	mapType := binding.Field.Type().(*reflect2.UnsafeMapType)
+	return &unknownFields{
+		field:       binding.Field,
+		mapType:     mapType,
+		elemDecoder: decoderOfType(ctx.append(binding.Field.Name()), mapType.Elem()),
+	}
+}
+
+func encoderOfUnknownFields(ctx *ctx, binding *Binding) *unknownFields {
+	mapType := binding.Field.Type().(*reflect2.UnsafeMapType)
+	return &unknownFields{
+		field:       binding.Field,
+		mapType:     mapType,
+		elemEncoder: encoderOfType(ctx.append(binding.Field.Name()), mapType.Elem()),
+	}
+}
+
+// decode reads the value of the unknown key into the map, the key must not
+// share the buffer of the iterator
+func (unknown *unknownFields) decode(ptr unsafe.Pointer, key string, iter *Iterator) {
+	mapPtr := unknown.field.UnsafeGet(ptr)
+	if unknown.mapType.UnsafeIsNil(mapPtr) {
+		unknown.mapType.UnsafeSet(mapPtr, unknown.mapType.UnsafeMakeMap(0))
+	}
+	keyPtr := unknown.mapType.Key().UnsafeNew()
+	*(*string)(keyPtr) = key
+	elem := unknown.mapType.Elem().UnsafeNew()
+	unknown.elemDecoder.Decode(elem, iter)
+	unknown.mapType.UnsafeSetIndex(mapPtr, keyPtr, elem)
+}
+
+// encode writes the keys of the map after the fields of the struct, the keys
+// of the fields are skipped. isNotFirst tells if a field has been written.
+func (unknown *unknownFields) encode(ptr unsafe.Pointer, stream *Stream, known []structFieldTo, isNotFirst bool) {
+	mapPtr := unknown.field.UnsafeGet(ptr)
+	if unknown.mapType.UnsafeIsNil(mapPtr) {
+		return
+	}
+	var keys []string
+	mapIter := unknown.mapType.UnsafeIterate(mapPtr)
+	for mapIter.HasNext() {
+		key, _ := mapIter.UnsafeNext()
+		keys = append(keys, *(*string)(key))
+	}
+	if stream.cfg.sortMapKeys {
+		sort.Strings(keys)
+	}
+	fields := stream.fields
+	for _, key := range keys {
+		if isKnownField(known, key) {
+			continue
+		}
+		selected, found := stream.selectField(key)
+		if !found {
+			continue
+		}
+		if isNotFirst {
+			stream.WriteMore()
+		}
+		stream.WriteObjectField(key)
+		stream.fields = selected
+		unknown.elemEncoder.Encode(unknown.mapType.UnsafeGetIndex(mapPtr, unsafe.Pointer(&key)), stream)
+		stream.fields = fields
+		isNotFirst = true
+	}
+}
+
+func isKnownField(known []structFieldTo, key string) bool {
+	for _, field := range known {
+		if field.toName == key {
+			return true
+		}
+	}
+	return false
+}
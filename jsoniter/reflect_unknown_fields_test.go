@@ -0,0 +1,89 @@
+package jsoniter
+
+import (
+	"testing"
+)
+
+type inlineEvent struct {
+	ID    int                    `json:"id"`
+	Name  string                 `json:"name,omitempty"`
+	Extra map[string]interface{} `json:",inline"`
+}
+
+type unknownRaw struct {
+	ID   int                   `json:"id"`
+	Rest map[string]RawMessage `json:"-,unknown"`
+}
+
+func TestInlineUnknownFields(t *testing.T) {
+	tests := []struct {
+		input  string
+		output string
+	}{
+		{`{"id":1,"name":"a"}`, `{"id":1,"name":"a"}`},
+		{`{"id":1,"x":2,"y":{"z":[1,"s"]}}`, `{"id":1,"x":2,"y":{"z":[1,"s"]}}`},
+		{`{"b":true,"id":1,"a":null}`, `{"id":1,"a":null,"b":true}`},
+		{`{"ID":1,"Name":"a"}`, `{"id":1,"name":"a"}`},
+		{`{}`, `{"id":0}`},
+	}
+	for _, api := range []API{ConfigCompatibleWithStandardLibrary, Config{SortMapKeys: true}.Froze()} {
+		for _, tt := range tests {
+			var event inlineEvent
+			if err := api.UnmarshalFromString(tt.input, &event); err != nil {
+				t.Fatalf("%s: %v", tt.input, err)
+			}
+			output, err := api.MarshalToString(event)
+			if err != nil || output != tt.output {
+				t.Fatalf("%s: expected %s, got %s %v", tt.input, tt.output, output, err)
+			}
+		}
+	}
+	var event inlineEvent
+	UnmarshalFromString(`{"id":1,"x":2}`, &event)
+	if _, found := event.Extra["x"]; !found || len(event.Extra) != 1 {
+		t.Fatalf("%+v", event.Extra)
+	}
+	// the known keys in the map are not written twice
+	event = inlineEvent{ID: 1, Extra: map[string]interface{}{"id": 2, "name": "x", "k": "v"}}
+	if output, _ := ConfigCompatibleWithStandardLibrary.MarshalToString(event); output != `{"id":1,"k":"v"}` {
+		t.Fatal(output)
+	}
+}
+
+func TestUnknownFieldsRaw(t *testing.T) {
+	var v unknownRaw
+	input := `{"id":1,"b": [1, 2],"a":"s"}`
+	if err := UnmarshalFromString(input, &v); err != nil {
+		t.Fatal(err)
+	}
+	if string(v.Rest["b"]) != `[1, 2]` || string(v.Rest["a"]) != `"s"` {
+		t.Fatalf("%q", v.Rest)
+	}
+	output, err := ConfigCompatibleWithStandardLibrary.MarshalToString(v)
+	if err != nil || output != `{"id":1,"a":"s","b":[1, 2]}` {
+		t.Fatalf("%s %v", output, err)
+	}
+	// with DisallowUnknownFields, the catch-all field still takes them
+	api := Config{DisallowUnknownFields: true}.Froze()
+	if err := api.UnmarshalFromString(input, &v); err != nil {
+		t.Fatal(err)
+	}
+	// MarshalFields selects the unknown keys too
+	output2, err := ConfigCompatibleWithStandardLibrary.(ViewAPI).MarshalFields(v, []string{"a"})
+	if err != nil || string(output2) != `{"a":"s"}` {
+		t.Fatalf("%s %v", output2, err)
+	}
+}
+
+func TestUnknownFieldsDuplicates(t *testing.T) {
+	api := Config{DuplicateKeys: DuplicateKeysError}.Froze()
+	var event inlineEvent
+	if err := api.UnmarshalFromString(`{"x":1,"x":2}`, &event); err == nil {
+		t.Fatal("expected a duplicate key error")
+	}
+	first := Config{DuplicateKeys: DuplicateKeysAllowFirst}.Froze()
+	event = inlineEvent{}
+	if err := first.UnmarshalFromString(`{"x":1,"x":2}`, &event); err != nil || event.Extra["x"] != float64(1) {
+		t.Fatalf("%+v %v", event, err)
+	}
+}
@@ -0,0 +1,54 @@
+package jsoniter
+
+import (
+	"strings"
+
+	"github.com/zhangdapeng520/zdpgo_json/reflect2"
+)
+
+// viewCacheKey keeps the encoders of a view apart from the others in the
+// encoder cache
+type viewCacheKey struct {
+	rtype uintptr
+	view  string
+}
+
+// encoderOfView is EncoderOf for the struct fields in the view
+func (cfg *frozenConfig) encoderOfView(typ reflect2.Type, view string) ValEncoder {
+	cacheKey := viewCacheKey{typ.RType(), view}
+	if encoder, found := cfg.encoderCache.Load(cacheKey); found {
+		return encoder.(ValEncoder)
+	}
+	ctx := &ctx{
+		frozenConfig: cfg,
+		prefix:       "",
+		decoders:     map[reflect2.Type]ValDecoder{},
+		encoders:     map[reflect2.Type]ValEncoder{},
+		view:         view,
+	}
+	encoder := encoderOfType(ctx, typ)
+	if typ.LikePtr() {
+		encoder = &onePtrEncoder{encoder}
+	}
+	cfg.encoderCache.Store(cacheKey, encoder)
+	return encoder
+}
+
+// inView tells if the field is in the view of ctx. A field tagged with
+// `view:"public,admin"` is only in these views, a field without a view tag
+// is in every view, and every field is in the empty view.
+func (ctx *ctx) inView(field reflect2.StructField) bool {
+	if ctx.view == "" {
+		return true
+	}
+	views, hasView := field.Tag().Lookup("view")
+	if !hasView {
+		return true
+	}
+	for _, view := range strings.Split(views, ",") {
+		if strings.TrimSpace(view) == ctx.view {
+			return true
+		}
+	}
+	return false
+}
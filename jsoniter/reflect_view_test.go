@@ -0,0 +1,85 @@
+package jsoniter
+
+import (
+	"bytes"
+	"testing"
+)
+
+type viewAccount struct {
+	ID     int    `json:"id"`
+	Email  string `json:"email" view:"admin, self"`
+	Secret string `json:"secret,omitempty" view:"admin"`
+}
+
+type viewTeam struct {
+	Name    string                 `json:"name"`
+	Owner   *viewAccount           `json:"owner"`
+	Members []viewAccount          `json:"members" view:"admin,member"`
+	ByName  map[string]viewAccount `json:"by_name"`
+	Any     interface{}            `json:"any"`
+}
+
+func TestMarshalView(t *testing.T) {
+	account := viewAccount{1, "a@b.c", "s"}
+	team := viewTeam{
+		Name:    "t",
+		Owner:   &account,
+		Members: []viewAccount{account},
+		ByName:  map[string]viewAccount{"b": account, "a": {2, "", ""}},
+		Any:     account,
+	}
+	tests := []struct {
+		name   string
+		value  interface{}
+		view   string
+		output string
+	}{
+		{"all fields", account, "", `{"id":1,"email":"a@b.c","secret":"s"}`},
+		{"admin", account, "admin", `{"id":1,"email":"a@b.c","secret":"s"}`},
+		{"self, with a space in the tag", account, "self", `{"id":1,"email":"a@b.c"}`},
+		{"unknown view", account, "public", `{"id":1}`},
+		{"pointer", &account, "public", `{"id":1}`},
+		{"slice", []viewAccount{account}, "self", `[{"id":1,"email":"a@b.c"}]`},
+		{"nested", team, "public",
+			`{"name":"t","owner":{"id":1},"by_name":{"a":{"id":2},"b":{"id":1}},"any":{"id":1}}`},
+		{"nested member", team, "member",
+			`{"name":"t","owner":{"id":1},"members":[{"id":1}],"by_name":{"a":{"id":2},"b":{"id":1}},"any":{"id":1}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the sorted maps write their values with a sub stream
+			for _, api := range []API{Config{SortMapKeys: true}.Froze(), ConfigCompatibleWithStandardLibrary} {
+				output, err := api.(ViewAPI).MarshalView(tt.value, tt.view)
+				if err != nil || string(output) != tt.output {
+					t.Fatalf("expected %s, got %s %v", tt.output, output, err)
+				}
+			}
+			var buf bytes.Buffer
+			encoder := ConfigCompatibleWithStandardLibrary.NewEncoder(&buf)
+			encoder.SetView(tt.view)
+			if err := encoder.Encode(tt.value); err != nil || buf.String() != tt.output+"\n" {
+				t.Fatalf("Encoder: expected %s, got %s %v", tt.output, buf.String(), err)
+			}
+		})
+	}
+}
+
+func TestMarshalViewCache(t *testing.T) {
+	account := viewAccount{1, "a@b.c", "s"}
+	// the encoders of a view do not replace the ones of the other views
+	for i := 0; i < 2; i++ {
+		for view, expected := range map[string]string{
+			"":      `{"id":1,"email":"a@b.c","secret":"s"}`,
+			"self":  `{"id":1,"email":"a@b.c"}`,
+			"other": `{"id":1}`,
+		} {
+			output, err := MarshalView(account, view)
+			if err != nil || string(output) != expected {
+				t.Fatalf("%q: expected %s, got %s", view, expected, output)
+			}
+		}
+		if output, _ := MarshalToString(account); output != `{"id":1,"email":"a@b.c","secret":"s"}` {
+			t.Fatalf("Marshal after a view %s", output)
+		}
+	}
+}
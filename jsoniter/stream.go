@@ -12,6 +12,8 @@ type Stream struct {
 	buf        []byte
 	Error      error
 	indention  int
+	view       string
+	fields     fieldSelection
 	Attachment interface{} // open for customized encoder
 }
 
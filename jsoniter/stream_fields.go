@@ -0,0 +1,85 @@
+package jsoniter
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// fieldSelection is the tree of the fields selected by MarshalFields, a nil
+// selection selects the whole value
+type fieldSelection map[string]fieldSelection
+
+// newFieldSelection parse the dotted paths, such as "owner.email". A '.' in
+// a name is escaped as "\.", and the '#' components are skipped, so that
+// "items.#.id" and "items.id" both select the id of each element.
+func newFieldSelection(paths []string) fieldSelection {
+	root := fieldSelection{}
+	for _, path := range paths {
+		var names []string
+		for _, name := range splitFieldPath(path) {
+			if name != "#" {
+				names = append(names, name)
+			}
+		}
+		node := root
+		for i, name := range names {
+			child, found := node[name]
+			if found && child == nil {
+				break // the whole value is already selected
+			}
+			if i == len(names)-1 {
+				node[name] = nil
+				break
+			}
+			if !found {
+				child = fieldSelection{}
+				node[name] = child
+			}
+			node = child
+		}
+	}
+	return root
+}
+
+func splitFieldPath(path string) []string {
+	var names []string
+	var name strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '\\':
+			if i+1 < len(path) {
+				i++
+				name.WriteByte(path[i])
+			}
+		case '.':
+			names = append(names, name.String())
+			name.Reset()
+		default:
+			name.WriteByte(c)
+		}
+	}
+	return append(names, name.String())
+}
+
+// selectField returns the selection below the field, and if the field is
+// selected. Everything is selected when MarshalFields is not used.
+func (stream *Stream) selectField(name string) (fieldSelection, bool) {
+	if stream.fields == nil {
+		return nil, true
+	}
+	selected, found := stream.fields[name]
+	return selected, found
+}
+
+// selectMapKey is selectField for a map key, that is encoded to know its name
+func (stream *Stream) selectMapKey(keyEncoder ValEncoder, key unsafe.Pointer) (fieldSelection, bool) {
+	if stream.fields == nil {
+		return nil, true
+	}
+	subStream := stream.cfg.BorrowStream(nil)
+	defer stream.cfg.ReturnStream(subStream)
+	keyEncoder.Encode(key, subStream)
+	subIter := stream.cfg.BorrowIterator(subStream.Buffer())
+	defer stream.cfg.ReturnIterator(subIter)
+	return stream.selectField(subIter.ReadString())
+}
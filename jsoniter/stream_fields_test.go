@@ -0,0 +1,97 @@
+package jsoniter
+
+import (
+	"testing"
+)
+
+type fieldsItem struct {
+	ID    int    `json:"id"`
+	Price int    `json:"price"`
+	Name  string `json:"name,omitempty"`
+}
+
+type fieldsOrder struct {
+	ID    int                    `json:"id"`
+	Owner *fieldsItem            `json:"owner"`
+	Items []fieldsItem           `json:"items"`
+	Tags  map[string]interface{} `json:"tags"`
+	Dot   int                    `json:"a.b"`
+	Any   interface{}            `json:"any"`
+}
+
+func TestMarshalFields(t *testing.T) {
+	order := fieldsOrder{
+		ID:    1,
+		Owner: &fieldsItem{ID: 2, Price: 3, Name: "o"},
+		Items: []fieldsItem{{4, 5, "x"}, {6, 7, ""}},
+		Tags:  map[string]interface{}{"k": map[string]int{"x": 1, "y": 2}, "l": 3},
+		Dot:   8,
+		Any:   fieldsItem{9, 10, "z"},
+	}
+	tests := []struct {
+		name   string
+		paths  []string
+		output string
+	}{
+		{"no paths", nil,
+			`{"id":1,"owner":{"id":2,"price":3,"name":"o"},"items":[{"id":4,"price":5,"name":"x"},{"id":6,"price":7}],` +
+				`"tags":{"k":{"x":1,"y":2},"l":3},"a.b":8,"any":{"id":9,"price":10,"name":"z"}}`},
+		{"top level", []string{"id"}, `{"id":1}`},
+		{"nested", []string{"id", "owner.name"}, `{"id":1,"owner":{"name":"o"}}`},
+		{"elements", []string{"items.price"}, `{"items":[{"price":5},{"price":7}]}`},
+		{"elements with #", []string{"items.#.id"}, `{"items":[{"id":4},{"id":6}]}`},
+		{"map keys", []string{"tags.k.y"}, `{"tags":{"k":{"y":2}}}`},
+		{"whole value wins", []string{"owner.id", "owner", "owner.name"}, `{"owner":{"id":2,"price":3,"name":"o"}}`},
+		{"escaped dot", []string{`a\.b`}, `{"a.b":8}`},
+		{"interface", []string{"any.id"}, `{"any":{"id":9}}`},
+		{"missing", []string{"nope", "owner.nope"}, `{"owner":{}}`},
+		{"field order is kept", []string{"any", "id"}, `{"id":1,"any":{"id":9,"price":10,"name":"z"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, api := range []API{Config{SortMapKeys: true}.Froze(), ConfigCompatibleWithStandardLibrary} {
+				output, err := api.(ViewAPI).MarshalFields(order, tt.paths)
+				if err != nil || string(output) != tt.output {
+					t.Fatalf("expected %s, got %s %v", tt.output, output, err)
+				}
+			}
+		})
+	}
+	// the selection is not kept by the stream
+	if output, _ := ConfigCompatibleWithStandardLibrary.MarshalToString(order.Owner); output != `{"id":2,"price":3,"name":"o"}` {
+		t.Fatalf("Marshal after MarshalFields %s", output)
+	}
+}
+
+func TestMarshalFieldsOrderedMap(t *testing.T) {
+	m := NewOrderedMap()
+	m.Set("b", map[string]int{"x": 1, "y": 2})
+	m.Set("a", 1)
+	m.Set("c", 2)
+	output, err := MarshalFields(m, []string{"c", "b.y"})
+	if err != nil || string(output) != `{"b":{"y":2},"c":2}` {
+		t.Fatalf("%s %v", output, err)
+	}
+}
+
+func TestSplitFieldPath(t *testing.T) {
+	tests := map[string][]string{
+		"a":         {"a"},
+		"a.b":       {"a", "b"},
+		`a\.b.c`:    {"a.b", "c"},
+		`a\\.b`:     {`a\`, "b"},
+		"a..b":      {"a", "", "b"},
+		`trailing\`: {`trailing`},
+	}
+	for path, expected := range tests {
+		names := splitFieldPath(path)
+		if len(names) != len(expected) {
+			t.Fatalf("%s: %q", path, names)
+		}
+		for i := range names {
+			if names[i] != expected[i] {
+				t.Fatalf("%s: %q", path, names)
+			}
+		}
+	}
+}
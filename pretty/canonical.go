@@ -0,0 +1,352 @@
+package pretty
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Canonical converts the input json into the canonical form of RFC 8785,
+// the JSON Canonicalization Scheme, which is byte stable for signing and
+// hashing. Insignificant space is removed, object keys are sorted by their
+// UTF-16 code units, numbers are written the way ECMAScript does and strings
+// use the minimal escaping. An error is returned when the input is not valid
+// I-JSON, such as a duplicate key, a lone surrogate or a number out of the
+// double range.
+func Canonical(json []byte) ([]byte, error) {
+	buf, i, err := appendCanonicalAny(make([]byte, 0, len(json)), json, skipSpace(json, 0))
+	if err != nil {
+		return nil, err
+	}
+	if i = skipSpace(json, i); i < len(json) {
+		return nil, canonicalError("invalid character", i)
+	}
+	return buf, nil
+}
+
+// CanonicalSHA256 returns the SHA-256 digest of the canonical form of the
+// input json.
+func CanonicalSHA256(json []byte) ([32]byte, error) {
+	buf, err := Canonical(json)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(buf), nil
+}
+
+func canonicalError(msg string, i int) error {
+	return fmt.Errorf("pretty: %s at offset %d", msg, i)
+}
+
+func skipSpace(json []byte, i int) int {
+	for ; i < len(json); i++ {
+		switch json[i] {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func appendCanonicalAny(buf, json []byte, i int) ([]byte, int, error) {
+	if i >= len(json) {
+		return buf, i, canonicalError("unexpected end of json", i)
+	}
+	switch c := json[i]; {
+	case c == '{':
+		return appendCanonicalObject(buf, json, i)
+	case c == '[':
+		return appendCanonicalArray(buf, json, i)
+	case c == '"':
+		str, j, err := parseCanonicalString(json, i)
+		if err != nil {
+			return buf, j, err
+		}
+		return appendCanonicalString(buf, str), j, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		return appendCanonicalNumber(buf, json, i)
+	}
+	for _, lit := range []string{"true", "false", "null"} {
+		if len(json)-i >= len(lit) && string(json[i:i+len(lit)]) == lit {
+			return append(buf, lit...), i + len(lit), nil
+		}
+	}
+	return buf, i, canonicalError("invalid character", i)
+}
+
+func appendCanonicalArray(buf, json []byte, i int) ([]byte, int, error) {
+	buf = append(buf, '[')
+	i = skipSpace(json, i+1)
+	if i < len(json) && json[i] == ']' {
+		return append(buf, ']'), i + 1, nil
+	}
+	for {
+		var err error
+		buf, i, err = appendCanonicalAny(buf, json, i)
+		if err != nil {
+			return buf, i, err
+		}
+		i = skipSpace(json, i)
+		if i >= len(json) {
+			return buf, i, canonicalError("unexpected end of json", i)
+		}
+		switch json[i] {
+		case ']':
+			return append(buf, ']'), i + 1, nil
+		case ',':
+			buf = append(buf, ',')
+			i = skipSpace(json, i+1)
+		default:
+			return buf, i, canonicalError("invalid character", i)
+		}
+	}
+}
+
+type canonicalMember struct {
+	key   string
+	units []uint16
+	value []byte
+}
+
+func appendCanonicalObject(buf, json []byte, i int) ([]byte, int, error) {
+	i = skipSpace(json, i+1)
+	if i < len(json) && json[i] == '}' {
+		return append(buf, '{', '}'), i + 1, nil
+	}
+	var members []canonicalMember
+	seen := make(map[string]bool)
+	for {
+		if i >= len(json) || json[i] != '"' {
+			return buf, i, canonicalError("invalid object key", i)
+		}
+		start := i
+		var key string
+		var err error
+		key, i, err = parseCanonicalString(json, i)
+		if err != nil {
+			return buf, i, err
+		}
+		if seen[key] {
+			return buf, start, canonicalError("duplicate key", start)
+		}
+		seen[key] = true
+		if i = skipSpace(json, i); i >= len(json) || json[i] != ':' {
+			return buf, i, canonicalError("missing colon", i)
+		}
+		var value []byte
+		value, i, err = appendCanonicalAny(nil, json, skipSpace(json, i+1))
+		if err != nil {
+			return buf, i, err
+		}
+		members = append(members, canonicalMember{
+			key:   key,
+			units: utf16.Encode([]rune(key)),
+			value: value,
+		})
+		if i = skipSpace(json, i); i >= len(json) {
+			return buf, i, canonicalError("unexpected end of json", i)
+		}
+		if json[i] == '}' {
+			i++
+			break
+		}
+		if json[i] != ',' {
+			return buf, i, canonicalError("invalid character", i)
+		}
+		i = skipSpace(json, i+1)
+	}
+	// keys are compared as arrays of UTF-16 code units
+	sort.Slice(members, func(a, b int) bool {
+		ka, kb := members[a].units, members[b].units
+		for n := 0; n < len(ka) && n < len(kb); n++ {
+			if ka[n] != kb[n] {
+				return ka[n] < kb[n]
+			}
+		}
+		return len(ka) < len(kb)
+	})
+	buf = append(buf, '{')
+	for n, member := range members {
+		if n > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendCanonicalString(buf, member.key)
+		buf = append(buf, ':')
+		buf = append(buf, member.value...)
+	}
+	return append(buf, '}'), i, nil
+}
+
+// parseCanonicalString unescapes the string that starts with the quote at
+// i, and returns the index after the closing quote.
+func parseCanonicalString(json []byte, i int) (string, int, error) {
+	var str []byte
+	for i++; i < len(json); {
+		c := json[i]
+		switch {
+		case c == '"':
+			return string(str), i + 1, nil
+		case c < ' ':
+			return "", i, canonicalError("control character in string", i)
+		case c == '\\':
+			if i+1 >= len(json) {
+				return "", i, canonicalError("unexpected end of json", i)
+			}
+			switch esc := json[i+1]; esc {
+			case '"', '\\', '/':
+				str = append(str, esc)
+			case 'b':
+				str = append(str, '\b')
+			case 'f':
+				str = append(str, '\f')
+			case 'n':
+				str = append(str, '\n')
+			case 'r':
+				str = append(str, '\r')
+			case 't':
+				str = append(str, '\t')
+			case 'u':
+				r, ok := parseHex4(json, i+2)
+				if !ok {
+					return "", i, canonicalError("invalid escape", i)
+				}
+				if utf16.IsSurrogate(r) {
+					r2 := rune(-1)
+					if i+7 < len(json) && json[i+6] == '\\' && json[i+7] == 'u' {
+						r2, _ = parseHex4(json, i+8)
+					}
+					if r = utf16.DecodeRune(r, r2); r == utf8.RuneError {
+						return "", i, canonicalError("lone surrogate", i)
+					}
+					i += 6
+				}
+				var rb [utf8.UTFMax]byte
+				str = append(str, rb[:utf8.EncodeRune(rb[:], r)]...)
+				i += 4
+			default:
+				return "", i, canonicalError("invalid escape", i)
+			}
+			i += 2
+		case c < utf8.RuneSelf:
+			str = append(str, c)
+			i++
+		default:
+			r, size := utf8.DecodeRune(json[i:])
+			if r == utf8.RuneError && size == 1 {
+				return "", i, canonicalError("invalid UTF-8", i)
+			}
+			str = append(str, json[i:i+size]...)
+			i += size
+		}
+	}
+	return "", i, canonicalError("unexpected end of json", i)
+}
+
+func parseHex4(json []byte, i int) (rune, bool) {
+	if i+4 > len(json) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(string(json[i:i+4]), 16, 32)
+	return rune(n), err == nil
+}
+
+// appendCanonicalString escapes only the quote, the backslash and the
+// control characters, using the short forms where there is one.
+func appendCanonicalString(buf []byte, str string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(str); i++ {
+		switch c := str[i]; c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			if c < ' ' {
+				buf = append(buf, '\\', 'u', '0', '0', hexp(c>>4), hexp(c&0xF))
+			} else {
+				buf = append(buf, c)
+			}
+		}
+	}
+	return append(buf, '"')
+}
+
+func appendCanonicalNumber(buf, json []byte, i int) ([]byte, int, error) {
+	start := i
+	if json[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(json) && json[i] == '0':
+		i++
+	case i < len(json) && json[i] >= '1' && json[i] <= '9':
+		i = skipDigits(json, i)
+	default:
+		return buf, start, canonicalError("invalid number", start)
+	}
+	if i < len(json) && json[i] == '.' {
+		j := skipDigits(json, i+1)
+		if j == i+1 {
+			return buf, start, canonicalError("invalid number", start)
+		}
+		i = j
+	}
+	if i < len(json) && (json[i] == 'e' || json[i] == 'E') {
+		i++
+		if i < len(json) && (json[i] == '+' || json[i] == '-') {
+			i++
+		}
+		j := skipDigits(json, i)
+		if j == i {
+			return buf, start, canonicalError("invalid number", start)
+		}
+		i = j
+	}
+	f, _ := strconv.ParseFloat(string(json[start:i]), 64)
+	if math.IsInf(f, 0) {
+		return buf, start, canonicalError("number out of range", start)
+	}
+	return appendES6Number(buf, f), i, nil
+}
+
+func skipDigits(json []byte, i int) int {
+	for ; i < len(json) && json[i] >= '0' && json[i] <= '9'; i++ {
+	}
+	return i
+}
+
+// appendES6Number writes the shortest form that round trips, like the
+// Number.prototype.toString of ECMAScript: no exponent in [1e-6, 1e21),
+// otherwise an exponent with no leading zeros, and -0 is 0.
+func appendES6Number(buf []byte, f float64) []byte {
+	if f == 0 {
+		return append(buf, '0')
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, 64)
+	if format == 'e' {
+		// e-07 to e-7
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf
+}
@@ -2,6 +2,7 @@ package pretty
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"math/rand"
@@ -569,3 +570,59 @@ func TestNaN(t *testing.T) {
 		}
 	}
 }
+
+func TestCanonical(t *testing.T) {
+	// the example of RFC 8785 section 3.2.2
+	out, err := Canonical([]byte(`{
+		"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		"literals": [null, true, false]
+	}`))
+	assertEqual(t, err, nil)
+	assertEqual(t, string(out), `{"literals":[null,true,false],`+
+		`"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],`+
+		`"string":"€$\u000f\nA'B\"\\\\\"/"}`)
+
+	// the sorting example of RFC 8785 section 3.2.3
+	out, err = Canonical([]byte(`{"\u20ac":"Euro Sign","\r":"Carriage Return",` +
+		`"\ufb33":"Hebrew Letter Dalet With Dagesh","1":"One",` +
+		`"\ud83d\ude00":"Emoji: Grinning Face","\u0080":"Control",` +
+		`"\u00f6":"Latin Small Letter O With Diaeresis"}`))
+	assertEqual(t, err, nil)
+	assertEqual(t, string(out), "{\"\\r\":\"Carriage Return\",\"1\":\"One\","+
+		"\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\","+
+		"\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\","+
+		"\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}")
+
+	numbers := map[string]string{
+		"-0":                      "0",
+		"1e21":                    "1e+21",
+		"999999999999999900000":   "999999999999999900000",
+		"5e-324":                  "5e-324",
+		"1.7976931348623157e308":  "1.7976931348623157e+308",
+		"9007199254740992":        "9007199254740992",
+		"0.000001":                "0.000001",
+		"1.5e-7":                  "1.5e-7",
+		"-123.456e2":              "-12345.6",
+		"295147905179352830000.0": "295147905179352830000",
+	}
+	for in, expect := range numbers {
+		out, err := Canonical([]byte(in))
+		assertEqual(t, err, nil)
+		assertEqual(t, string(out), expect)
+	}
+
+	invalid := []string{
+		``, `{"a":1,"a":2}`, `"\ud800"`, "\"\xff\"", `1e400`, `[1,]`, `{"a" 1}`,
+		`01`, `1.`, `tru`, `[1] x`, "\"\x01\"",
+	}
+	for _, in := range invalid {
+		if _, err := Canonical([]byte(in)); err == nil {
+			t.Fatalf("expected an error for %q", in)
+		}
+	}
+
+	sum, err := CanonicalSHA256([]byte(` { "b" : 2, "a" : [ 1.0 ] } `))
+	assertEqual(t, err, nil)
+	assertEqual(t, sum, sha256.Sum256([]byte(`{"a":[1],"b":2}`)))
+}
@@ -0,0 +1,381 @@
+package query
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zhangdapeng520/zdpgo_json/pretty"
+)
+
+// The aggregate modifiers operate on arrays, such as the result of a '#'
+// projection:
+//
+//	friends.#.age|@sum                      -> 159
+//	friends.#.age|@avg                      -> 53
+//	friends.#.age|@max                      -> 68
+//	friends|@sort:{"by":"age","desc":true}  -> [{..."age":68},...]
+//	friends|@groupby:last                   -> {"Murphy":[...],"Craig":[...]}
+//
+// Integers are accumulated exactly, other numbers use float64. Elements that
+// are not numbers are ignored by @sum, @avg, @min and @max.
+
+// numberAcc accumulates json numbers. The sum stays an exact big.Int until
+// a number with a fraction, or one that is too large to expand, is added.
+type numberAcc struct {
+	n     int
+	exact bool
+	isum  big.Int
+	fsum  float64
+}
+
+func newNumberAcc() *numberAcc {
+	return &numberAcc{exact: true}
+}
+
+func (a *numberAcc) add(v Result) {
+	a.n++
+	if a.exact {
+		if d, err := v.decimal(""); err == nil && d.isInt() {
+			var x big.Int
+			x.SetString(d.String(), 10)
+			a.isum.Add(&a.isum, &x)
+			return
+		}
+		a.exact = false
+		a.fsum, _ = new(big.Float).SetInt(&a.isum).Float64()
+	}
+	a.fsum += v.Float()
+}
+
+// numbers calls the iterator for each number of a json array.
+func numbers(json string, iter func(v Result)) bool {
+	res := Parse(json)
+	if !res.IsArray() {
+		return false
+	}
+	res.ForEach(func(_, value Result) bool {
+		if value.Type == Number {
+			iter(value)
+		}
+		return true
+	})
+	return true
+}
+
+// formatFloat formats a float as a json number.
+func formatFloat(f float64) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "null"
+	}
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// @sum adds the numbers of an array.
+//
+//	[1,2,3.5] -> 6.5
+func modSum(json, arg string) string {
+	acc := newNumberAcc()
+	if !numbers(json, acc.add) {
+		return ""
+	}
+	if acc.exact {
+		return acc.isum.String()
+	}
+	return formatFloat(acc.fsum)
+}
+
+// @avg returns the mean of the numbers of an array, or null when there are
+// no numbers.
+//
+//	[1,2,3,4] -> 2.5
+func modAvg(json, arg string) string {
+	acc := newNumberAcc()
+	if !numbers(json, acc.add) {
+		return ""
+	}
+	if acc.n == 0 {
+		return "null"
+	}
+	if acc.exact {
+		r := new(big.Rat).SetFrac(&acc.isum, big.NewInt(int64(acc.n)))
+		if r.IsInt() {
+			return r.Num().String()
+		}
+		f, _ := r.Float64()
+		return formatFloat(f)
+	}
+	return formatFloat(acc.fsum / float64(acc.n))
+}
+
+// @min returns the smallest number of an array, or the smallest string when
+// the array has no numbers. Numbers are compared exactly.
+//
+//	[3,1,2] -> 1
+func modMin(json, arg string) string {
+	return extreme(json, -1)
+}
+
+// @max returns the largest number of an array, or the largest string when
+// the array has no numbers.
+//
+//	[3,1,2] -> 3
+func modMax(json, arg string) string {
+	return extreme(json, 1)
+}
+
+func extreme(json string, sign int) string {
+	res := Parse(json)
+	if !res.IsArray() {
+		return ""
+	}
+	var num, str Result
+	res.ForEach(func(_, value Result) bool {
+		switch value.Type {
+		case Number:
+			if !num.Exists() || compareValues(value, num)*sign > 0 {
+				num = value
+			}
+		case String:
+			if !str.Exists() || compareValues(value, str)*sign > 0 {
+				str = value
+			}
+		}
+		return true
+	})
+	switch {
+	case num.Exists():
+		return num.Raw
+	case str.Exists():
+		return str.Raw
+	}
+	return "null"
+}
+
+// @count returns the number of elements of an array or members of an
+// object.
+//
+//	[1,2,3] -> 3
+func modCount(json, arg string) string {
+	res := Parse(json)
+	if !res.Exists() {
+		return "0"
+	}
+	if !res.IsArray() && !res.IsObject() {
+		return "1"
+	}
+	var n int
+	res.ForEach(func(_, _ Result) bool {
+		n++
+		return true
+	})
+	return strconv.Itoa(n)
+}
+
+// @distinct removes the duplicate elements of an array, keeping the first
+// of each. Numbers are equal when their values are, such as 1 and 1.0.
+//
+//	[1,"a",1.0,"a",2] -> [1,"a",2]
+func modDistinct(json, arg string) string {
+	res := Parse(json)
+	if !res.IsArray() {
+		return json
+	}
+	seen := make(map[string]bool)
+	out := []byte{'['}
+	res.ForEach(func(_, value Result) bool {
+		key := distinctKey(value)
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+		if len(out) > 1 {
+			out = append(out, ',')
+		}
+		out = append(out, value.Raw...)
+		return true
+	})
+	out = append(out, ']')
+	return bytesString(out)
+}
+
+// distinctKey returns a key that is equal for equal json values.
+func distinctKey(v Result) string {
+	switch v.Type {
+	case String:
+		return "s" + v.Str
+	case Number:
+		if d, err := v.decimal(""); err == nil {
+			return "n" + d.String()
+		}
+		return "n" + v.Raw
+	case JSON:
+		return "j" + string(pretty.Ugly([]byte(v.Raw)))
+	}
+	return "v" + v.Raw
+}
+
+// @sort sorts the elements of an array. The "by" arg is a path of the
+// elements that is used as the sort key, and "desc" reverses the order.
+// Values of different types are ordered as null, false, true, numbers,
+// strings, then objects and arrays.
+//
+//	[3,1,2] -> [1,2,3]
+//	@sort:{"by":"age","desc":true}
+func modSort(json, arg string) string {
+	return defaultEngine.modSort(json, arg)
+}
+
+// modSort is @sort with the "by" path evaluated by the engine.
+func (e *Engine) modSort(json, arg string) string {
+	res := Parse(json)
+	if !res.IsArray() {
+		return json
+	}
+	var by string
+	var desc bool
+	if arg != "" {
+		Parse(arg).ForEach(func(key, value Result) bool {
+			switch key.String() {
+			case "by":
+				by = value.String()
+			case "desc":
+				desc = value.Bool()
+			}
+			return true
+		})
+	}
+	type elem struct{ key, value Result }
+	var elems []elem
+	res.ForEach(func(_, value Result) bool {
+		key := value
+		if by != "" {
+			key = value.getPath(e, by, nil)
+		}
+		elems = append(elems, elem{key, value})
+		return true
+	})
+	sort.SliceStable(elems, func(i, j int) bool {
+		if desc {
+			return compareValues(elems[j].key, elems[i].key) < 0
+		}
+		return compareValues(elems[i].key, elems[j].key) < 0
+	})
+	out := []byte{'['}
+	for i, el := range elems {
+		if i > 0 {
+			out = append(out, ',')
+		}
+		out = append(out, el.value.Raw...)
+	}
+	out = append(out, ']')
+	return bytesString(out)
+}
+
+// sortRank orders the json types for compareValues. Missing values sort
+// first.
+func sortRank(v Result) int {
+	switch {
+	case !v.Exists():
+		return 0
+	case v.Type == Null:
+		return 1
+	case v.Type == False:
+		return 2
+	case v.Type == True:
+		return 3
+	case v.Type == Number:
+		return 4
+	case v.Type == String:
+		return 5
+	}
+	return 6
+}
+
+// compareValues compares two json values and returns -1, 0 or +1.
+func compareValues(a, b Result) int {
+	ra, rb := sortRank(a), sortRank(b)
+	switch {
+	case ra < rb:
+		return -1
+	case ra > rb:
+		return 1
+	}
+	switch a.Type {
+	case Number:
+		if c, ok := compareNumbers(a.Raw, b.Raw); ok {
+			return c
+		}
+		switch {
+		case a.Num < b.Num:
+			return -1
+		case a.Num > b.Num:
+			return 1
+		}
+		return 0
+	case String:
+		return strings.Compare(a.Str, b.Str)
+	case JSON:
+		return strings.Compare(a.Raw, b.Raw)
+	}
+	return 0
+}
+
+// @groupby groups the elements of an array into an object by the value of
+// a path, in the order that the keys are first seen. Elements without the
+// path are left out.
+//
+//	[{"k":"a","v":1},{"k":"b","v":2},{"k":"a","v":3}] ->
+//	{"a":[{"k":"a","v":1},{"k":"a","v":3}],"b":[{"k":"b","v":2}]}
+func modGroupBy(json, arg string) string {
+	return defaultEngine.modGroupBy(json, arg)
+}
+
+// modGroupBy is @groupby with the path evaluated by the engine.
+func (e *Engine) modGroupBy(json, arg string) string {
+	res := Parse(json)
+	if !res.IsArray() {
+		return json
+	}
+	path := arg
+	if a := Parse(arg); a.Type == String && len(arg) > 0 && arg[0] == '"' {
+		path = a.Str
+	}
+	var keys []string
+	groups := make(map[string][]byte)
+	res.ForEach(func(_, value Result) bool {
+		key := value
+		if path != "" {
+			key = value.getPath(e, path, nil)
+		}
+		if !key.Exists() {
+			return true
+		}
+		k := key.String()
+		group, ok := groups[k]
+		if !ok {
+			keys = append(keys, k)
+		} else {
+			group = append(group, ',')
+		}
+		groups[k] = append(group, value.Raw...)
+		return true
+	})
+	out := []byte{'{'}
+	for i, k := range keys {
+		if i > 0 {
+			out = append(out, ',')
+		}
+		out = appendJSONString(out, k)
+		out = append(out, ':', '[')
+		out = append(out, groups[k]...)
+		out = append(out, ']')
+	}
+	out = append(out, '}')
+	return bytesString(out)
+}
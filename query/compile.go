@@ -0,0 +1,329 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPath 查询路径的语法错误
+var ErrInvalidPath = errors.New("query: invalid path")
+
+// Path 预先编译好的查询路径。
+// 编译后的路径可以被多个goroutine并发复用，每次查询时不再需要重新解析路径。
+// 路径在编译时就会解析修饰符、子选择器和过滤器，引擎的选项也在编译时确定。
+type Path struct {
+	path string
+	e    *Engine
+
+	// head is '@' or '!' when the path starts with a modifier or a static
+	// value. The remaining path is stored in rest, and next is the compiled
+	// form of rest without its leading '.' or '|'.
+	head    byte
+	modName string
+	modArgs string
+	static  string
+	rest    string
+	next    *Path
+
+	// subs are the selectors of a '[path1,path2]' or '{"a":path1}' path,
+	// subPaths holds their compiled forms.
+	subs     []subSelector
+	subPaths []*Path
+
+	// comp is the compiled component chain of a plain path.
+	comp *pathComp
+
+	// deep is set when the path has a '**' or '..' component.
+	deep *deepPath
+}
+
+// pathComp is a compiled path component. It keeps the results of both
+// parseObjectPath and parseArrayPath because the json decides which one is
+// used when the path is evaluated.
+type pathComp struct {
+	obj     objectPathResult
+	objNext *pathComp
+	objPipe *Path
+
+	arr     arrayPathResult
+	arrComp arrayPathComp
+}
+
+// arrayPathComp holds the compiled sub paths of an arrayPathResult.
+type arrayPathComp struct {
+	next     *pathComp // rp.path, for nested objects and arrays
+	pipe     *Path     // rp.pipe
+	query    *Path     // rp.query.path
+	more     *Path     // rp.path, applied to the query matches
+	morePipe *Path     // pipe split from rp.path
+	alog     *Path     // rp.alogkey
+	alogPipe *Path     // pipe split from rp.alogkey
+}
+
+// compiler memoizes compiled paths by their text. Both components of a
+// path are compiled from suffixes of the same string, so this keeps the
+// work linear in the length of the path.
+type compiler struct {
+	e     *Engine
+	paths map[string]*Path
+	comps map[string]*pathComp
+}
+
+// Compile 使用默认引擎编译查询路径，路径的语法错误会在编译时返回。
+// 返回的 Path 可以被并发复用，适合对大量文档重复执行同样的查询。
+func Compile(path string) (*Path, error) {
+	return defaultEngine.Compile(path)
+}
+
+// Compile 编译查询路径，编译后的路径使用该引擎的修饰符和选项。
+func (e *Engine) Compile(path string) (*Path, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: empty path", ErrInvalidPath)
+	}
+	c := compiler{
+		e:     e,
+		paths: make(map[string]*Path),
+		comps: make(map[string]*pathComp),
+	}
+	return c.path(path)
+}
+
+// MustCompile 与Compile相同，但路径无效时会panic。
+func MustCompile(path string) *Path {
+	p, err := Compile(path)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String 返回编译前的路径
+func (p *Path) String() string {
+	return p.path
+}
+
+// Get 在json中搜索编译好的路径，结果与 Get(json, path) 相同。
+func (p *Path) Get(json string) Result {
+	if p.e.checkLimits(json) != nil {
+		return Result{}
+	}
+	return getPath(p.e, json, p.path, p)
+}
+
+// GetBytes 在json中搜索编译好的路径，结果与 GetBytes(json, path) 相同。
+func (p *Path) GetBytes(json []byte) Result {
+	if p.e.checkLimits(bytesString(json)) != nil {
+		return Result{}
+	}
+	return getBytes(p.e, json, p.path, p)
+}
+
+// GetE 与 Get 相同，但json超过引擎的限制时返回 *LimitError。
+func (p *Path) GetE(json string) (Result, error) {
+	if err := p.e.checkLimits(json); err != nil {
+		return Result{}, err
+	}
+	return getPath(p.e, json, p.path, p), nil
+}
+
+// GetBytesE 与 GetBytes 相同，但json超过引擎的限制时返回 *LimitError。
+func (p *Path) GetBytesE(json []byte) (Result, error) {
+	if err := p.e.checkLimits(bytesString(json)); err != nil {
+		return Result{}, err
+	}
+	return getBytes(p.e, json, p.path, p), nil
+}
+
+// nextPath returns the compiled remainder of the path, or nil when the
+// path was not compiled.
+func (p *Path) nextPath() *Path {
+	if p == nil {
+		return nil
+	}
+	return p.next
+}
+
+// deepOf returns the deep component of the path, the compiled one when p
+// is not nil.
+func deepOf(path string, p *Path) *deepPath {
+	if p != nil {
+		return p.deep
+	}
+	return parseDeepPath(path)
+}
+
+// execHead runs the compiled modifier or static value at the head of the
+// path. It mirrors execModifier and execStatic.
+func (p *Path) execHead(json string) (pathOut, res string, ok bool) {
+	switch p.head {
+	case '@':
+		if fn, ok := p.e.modifier(p.modName); ok {
+			return p.rest, fn(json, p.modArgs), true
+		}
+	case '!':
+		return p.rest, p.static, true
+	}
+	return "", "", false
+}
+
+func (c *compiler) path(path string) (*Path, error) {
+	if p, ok := c.paths[path]; ok {
+		return p, nil
+	}
+	p := &Path{path: path, e: c.e}
+	plain := path
+	if len(path) > 1 {
+		if d := parseDeepPath(path); d != nil {
+			if err := c.deep(d); err != nil {
+				return nil, err
+			}
+			p.deep = d
+			c.paths[path] = p
+			return p, nil
+		}
+		switch {
+		case path[0] == '@' && !c.e.modifiersDisabled():
+			name, args, rest := parseModifier(path)
+			if _, ok := c.e.modifier(name); !ok {
+				return nil, fmt.Errorf("%w: unknown modifier %q",
+					ErrInvalidPath, name)
+			}
+			p.head, p.modName, p.modArgs = '@', name, args
+			return c.head(p, rest)
+		case path[0] == '!' && !c.e.staticDisabled():
+			if rest, res, ok := execStatic("", path); ok {
+				p.head, p.static = '!', res
+				return c.head(p, rest)
+			}
+		}
+		if (path[0] == '[' && !isSliceComp(path)) || path[0] == '{' {
+			subs, rest, ok := parseSubSelectors(path)
+			if !ok {
+				return nil, fmt.Errorf("%w: unterminated selector %q",
+					ErrInvalidPath, path)
+			}
+			p.subs, p.rest = subs, rest
+			p.subPaths = make([]*Path, len(subs))
+			for i, sub := range subs {
+				sp, err := c.path(sub.path)
+				if err != nil {
+					return nil, err
+				}
+				p.subPaths[i] = sp
+			}
+			if len(rest) == 0 || rest[0] == '|' || rest[0] == '.' {
+				return c.head(p, rest)
+			}
+			plain = rest
+		}
+	}
+	if len(plain) >= 2 && plain[0] == '.' && plain[1] == '.' {
+		plain = plain[2:]
+	}
+	comp, err := c.comp(plain)
+	if err != nil {
+		return nil, err
+	}
+	p.comp = comp
+	c.paths[path] = p
+	return p, nil
+}
+
+// head compiles the path that follows a modifier, static value or
+// selector.
+func (c *compiler) head(p *Path, rest string) (*Path, error) {
+	p.rest = rest
+	if len(rest) > 0 && (rest[0] == '|' || rest[0] == '.') {
+		next, err := c.path(rest[1:])
+		if err != nil {
+			return nil, err
+		}
+		p.next = next
+	}
+	c.paths[p.path] = p
+	return p, nil
+}
+
+func (c *compiler) comp(path string) (*pathComp, error) {
+	if pc, ok := c.comps[path]; ok {
+		return pc, nil
+	}
+	var err error
+	pc := &pathComp{
+		obj: parseObjectPath(c.e, path),
+		arr: parseArrayPath(c.e, path),
+	}
+	if pc.obj.more {
+		if pc.objNext, err = c.comp(pc.obj.path); err != nil {
+			return nil, err
+		}
+	} else if pc.obj.piped {
+		if pc.objPipe, err = c.path(pc.obj.pipe); err != nil {
+			return nil, err
+		}
+	}
+	if err = c.arrayComp(path, &pc.arr, &pc.arrComp); err != nil {
+		return nil, err
+	}
+	c.comps[path] = pc
+	return pc, nil
+}
+
+func (c *compiler) arrayComp(path string, rp *arrayPathResult,
+	cp *arrayPathComp) (err error) {
+	if rp.query.on {
+		if _, _, _, _, _, _, ok := parseQuery(path); !ok {
+			return fmt.Errorf("%w: unbalanced query %q", ErrInvalidPath, path)
+		}
+		if rp.query.err != nil {
+			return rp.query.err
+		}
+		if rp.query.expr != nil {
+			err = rp.query.expr.checkRegex(c.e)
+		} else {
+			err = checkRegex(c.e, rp.query.op, rp.query.value)
+		}
+		if err != nil {
+			return err
+		}
+		if cp.query, err = c.path(rp.query.path); err != nil {
+			return err
+		}
+		if rp.more {
+			left, right, ok := splitPossiblePipe(rp.path)
+			if ok {
+				if cp.morePipe, err = c.path(right); err != nil {
+					return err
+				}
+			} else {
+				left = rp.path
+			}
+			if cp.more, err = c.path(left); err != nil {
+				return err
+			}
+		}
+	}
+	if rp.more {
+		if cp.next, err = c.comp(rp.path); err != nil {
+			return err
+		}
+	} else if rp.piped {
+		if cp.pipe, err = c.path(rp.pipe); err != nil {
+			return err
+		}
+	}
+	if rp.alogok {
+		left, right, ok := splitPossiblePipe(rp.alogkey)
+		if ok {
+			if cp.alogPipe, err = c.path(right); err != nil {
+				return err
+			}
+		} else {
+			left = rp.alogkey
+		}
+		if cp.alog, err = c.path(left); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package query
+
+import "strings"
+
+// A '**' path component, or '..' in the middle of a path, searches every
+// depth below the current node:
+//
+//	**.id              -> every "id" member, at any depth
+//	items..price       -> every "price" below "items", same as items.**.price
+//	**.tags.0|@count   -> the first tag of every "tags" array, counted
+//	items.#..price     -> every "price" below the elements of "items"
+//
+// The path that follows the component is applied to the current node and to
+// each of its descendants, in document order. The matches are returned as an
+// array with Indexes populated, like a '#' projection. A pipe after the
+// component applies to that array. A '..' at the start of a path still
+// selects the JSON Lines mode.
+
+// deepPath is a path split at its first '**' or '..' component. The
+// compiled forms of its parts are set when the path is compiled.
+type deepPath struct {
+	prefix   string // the path to the node that is searched, "" for the root
+	elements bool   // the prefix ended with '#', the node itself is skipped
+	left     string // the path applied at every depth
+	right    string // the path applied to the matches, after a pipe
+	piped    bool
+
+	base, leftPath, rightPath *Path
+}
+
+// parseDeepPath returns the split path, or nil when the path has no '**'
+// or '..' component. Most paths are rejected without scanning them.
+func parseDeepPath(path string) *deepPath {
+	if !strings.Contains(path, "..") && !strings.Contains(path, "**") {
+		return nil
+	}
+	prefix, rest, ok := splitDeepPath(path)
+	if !ok {
+		return nil
+	}
+	d := &deepPath{prefix: prefix}
+	d.prefix, d.elements = trimElements(prefix)
+	d.left, d.right, d.piped = splitPossiblePipe(rest)
+	if !d.piped {
+		d.left = rest
+	}
+	return d
+}
+
+// trimElements removes a trailing '#' component, as in items.#..price, so
+// the elements of the array are searched instead of its length.
+func trimElements(prefix string) (string, bool) {
+	if prefix == "#" {
+		return "", true
+	}
+	if !strings.HasSuffix(prefix, ".#") {
+		return prefix, false
+	}
+	n := 0
+	for i := len(prefix) - 3; i >= 0 && prefix[i] == '\\'; i-- {
+		n++
+	}
+	if n%2 == 1 {
+		// an escaped '.'
+		return prefix, false
+	}
+	return prefix[:len(prefix)-2], true
+}
+
+// splitDeepPath splits the path at its first top level '**' or '..'
+// component. The prefix is the path to the node that is searched and the
+// rest is the path that is applied at every depth.
+func splitDeepPath(path string) (prefix, rest string, ok bool) {
+	depth := 0
+	comp := 0 // the start of the current component
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			i++
+		case '"':
+			raw, _ := tostr(path[i:])
+			i += len(raw) - 1
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '.':
+			if depth != 0 {
+				continue
+			}
+			if i > 0 && i+2 < len(path) && path[i+1] == '.' &&
+				path[i+2] != '.' {
+				return path[:i], path[i+2:], true
+			}
+			comp = i + 1
+		case '*':
+			if depth == 0 && i == comp &&
+				i+3 < len(path) && path[i+1] == '*' && path[i+2] == '.' {
+				if i == 0 {
+					return "", path[3:], true
+				}
+				return path[:i-1], path[i+3:], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// getDeep searches the rest of the path at every depth below the prefix.
+func getDeep(e *Engine, json string, d *deepPath) Result {
+	var base Result
+	if d.prefix == "" {
+		base = Parse(json)
+	} else {
+		base = getPath(e, json, d.prefix, d.base)
+	}
+	if !base.Exists() {
+		return Result{}
+	}
+	var indexes []int
+	raw := []byte{'['}
+	first := true
+	jpDescend(base, func(n Result) {
+		skip := first && d.elements
+		first = false
+		if skip || n.Type != JSON || (n.Indexes != nil && n.Raw == base.Raw) {
+			// a projected base has no offset of its own, only its
+			// elements do.
+			return
+		}
+		res := n.getPath(e, d.left, d.leftPath)
+		if !res.Exists() {
+			return
+		}
+		if len(indexes) > 0 {
+			raw = append(raw, ',')
+		}
+		raw = append(raw, res.Raw...)
+		indexes = append(indexes, res.Index)
+	})
+	raw = append(raw, ']')
+	res := Result{Type: JSON, Raw: string(raw), Indexes: indexes}
+	if indexes == nil {
+		res.Indexes = []int{}
+	}
+	if d.piped {
+		res = getPath(e, res.Raw, d.right, d.rightPath)
+		res.Index = 0
+		res.Indexes = nil
+	}
+	return res
+}
+
+// deep compiles the parts of a deep path.
+func (c *compiler) deep(d *deepPath) (err error) {
+	if d.prefix != "" {
+		if d.base, err = c.path(d.prefix); err != nil {
+			return err
+		}
+	}
+	if d.left != "" {
+		if d.leftPath, err = c.path(d.left); err != nil {
+			return err
+		}
+	}
+	if d.piped && d.right != "" {
+		d.rightPath, err = c.path(d.right)
+	}
+	return err
+}
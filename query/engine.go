@@ -0,0 +1,202 @@
+package query
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Options 查询引擎的选项
+type Options struct {
+	DisableModifiers bool // 禁用 @modifier 修饰符语法
+	DisableStatic    bool // 禁用 !value 静态值语法
+	PreciseNumbers   bool // 过滤器 #(...) 中使用精确的十进制数字比较
+	DisableRegex     bool // 禁用过滤器中的 ~= 正则表达式运算符，适用于不可信的路径
+
+	// 以下限制用于不可信的json，0表示不限制。设置任意限制后，每次查询前
+	// 都会完整扫描一遍json，超过限制时查询结果不存在，GetE 等方法会返回
+	// *LimitError，详见 CheckLimits。
+	// 默认引擎和包级别的函数没有限制，需要通过 NewEngine 设置。
+	MaxDepth         int // 对象和数组的最大嵌套深度
+	MaxBytes         int // json的最大字节数
+	MaxStringLength  int // 字符串反转义后的最大字节数
+	MaxNumberLength  int // 数字不含负号的最大字符数
+	MaxArrayElements int // 数组的最大元素个数
+	MaxObjectKeys    int // 对象的最大键个数
+}
+
+// Engine 查询引擎，拥有独立的修饰符集合和选项。
+// 修饰符可以在运行时并发地注册和删除，不会与正在执行的查询产生数据竞争，
+// 因此可以为每个租户创建一个引擎。包级别的函数使用默认引擎。
+type Engine struct {
+	opts   Options
+	global bool // use the package level options, only for defaultEngine
+
+	mu   sync.Mutex   // serializes the writers of mods
+	mods atomic.Value // map[string]func(json, arg string) string
+}
+
+// defaultEngine is used by the package level functions. It reads the
+// package level DisableModifiers variable.
+var defaultEngine = &Engine{global: true}
+
+func init() {
+	defaultEngine.mods.Store(defaultEngine.builtinModifiers())
+}
+
+// NewEngine 创建一个新的查询引擎，引擎包含所有内置的修饰符。
+// opts为nil时使用默认选项，选项在引擎创建后不能修改。
+func NewEngine(opts *Options) *Engine {
+	e := &Engine{}
+	if opts != nil {
+		e.opts = *opts
+	}
+	e.mods.Store(e.builtinModifiers())
+	return e
+}
+
+// DefaultEngine 返回包级别函数使用的默认引擎。默认引擎的 DisableModifiers
+// 选项来自包级别的变量，其他选项都为零值。
+func DefaultEngine() *Engine {
+	return defaultEngine
+}
+
+// Options 返回引擎的选项
+func (e *Engine) Options() Options {
+	if e.global {
+		return Options{
+			DisableModifiers: DisableModifiers,
+		}
+	}
+	return e.opts
+}
+
+// AddModifier 将一个自定义修饰符绑定到该引擎，同名的修饰符会被替换。
+func (e *Engine) AddModifier(name string, fn func(json, arg string) string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	mods := copyModifiers(e.loadModifiers())
+	mods[name] = fn
+	e.mods.Store(mods)
+}
+
+// RemoveModifier 从该引擎中删除指定的修饰符
+func (e *Engine) RemoveModifier(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	mods := copyModifiers(e.loadModifiers())
+	delete(mods, name)
+	e.mods.Store(mods)
+}
+
+// ModifierExists 当该引擎中存在指定的修饰符时返回true
+func (e *Engine) ModifierExists(name string) bool {
+	_, ok := e.modifier(name)
+	return ok
+}
+
+// Get 使用该引擎在json中搜索指定路径
+func (e *Engine) Get(json, path string) Result {
+	if e.checkLimits(json) != nil {
+		return Result{}
+	}
+	return getPath(e, json, path, nil)
+}
+
+// GetBytes 使用该引擎在json中搜索指定路径
+func (e *Engine) GetBytes(json []byte, path string) Result {
+	if e.checkLimits(bytesString(json)) != nil {
+		return Result{}
+	}
+	return getBytes(e, json, path, nil)
+}
+
+// Path 返回结果在原始json中的路径，与 Result.Path 相同，但使用该引擎的选项，
+// 例如禁用修饰符时根路径返回空字符串而不是 "@this"。
+func (e *Engine) Path(t Result, json string) string {
+	return t.path(e, json)
+}
+
+// Paths 返回结果中每个元素在原始json中的路径，与 Result.Paths 相同，
+// 但使用该引擎的选项。
+func (e *Engine) Paths(t Result, json string) []string {
+	return t.paths(e, json)
+}
+
+// GetMany 使用该引擎在json中搜索多个路径
+func (e *Engine) GetMany(json string, path ...string) []Result {
+	return newMultiPath(e, path).Get(json)
+}
+
+// GetE 与 Get 相同，但json超过引擎的限制时返回 *LimitError，
+// 而不是不存在的结果。
+func (e *Engine) GetE(json, path string) (Result, error) {
+	if err := e.checkLimits(json); err != nil {
+		return Result{}, err
+	}
+	return getPath(e, json, path, nil), nil
+}
+
+// GetBytesE 与 GetBytes 相同，但json超过引擎的限制时返回 *LimitError。
+func (e *Engine) GetBytesE(json []byte, path string) (Result, error) {
+	if err := e.checkLimits(bytesString(json)); err != nil {
+		return Result{}, err
+	}
+	return getBytes(e, json, path, nil), nil
+}
+
+// GetManyE 与 GetMany 相同，但json超过引擎的限制时返回 *LimitError。
+func (e *Engine) GetManyE(json string, path ...string) ([]Result, error) {
+	return newMultiPath(e, path).GetE(json)
+}
+
+// GetManyBytes 使用该引擎在json中搜索多个路径
+func (e *Engine) GetManyBytes(json []byte, path ...string) []Result {
+	return newMultiPath(e, path).GetBytes(json)
+}
+
+func (e *Engine) loadModifiers() map[string]func(json, arg string) string {
+	return e.mods.Load().(map[string]func(json, arg string) string)
+}
+
+func (e *Engine) modifier(name string) (func(json, arg string) string, bool) {
+	fn, ok := e.loadModifiers()[name]
+	return fn, ok
+}
+
+func (e *Engine) modifiersDisabled() bool {
+	if e.global {
+		return DisableModifiers
+	}
+	return e.opts.DisableModifiers
+}
+
+func (e *Engine) staticDisabled() bool {
+	return e.opts.DisableStatic
+}
+
+func (e *Engine) regexDisabled() bool {
+	return e.opts.DisableRegex
+}
+
+func (e *Engine) preciseNumbers() bool {
+	return e.opts.PreciseNumbers
+}
+
+// builtinModifiers returns a copy of the built-in modifiers, those that
+// evaluate paths of their own are bound to the engine so that the paths
+// follow its options.
+func (e *Engine) builtinModifiers() map[string]func(json, arg string) string {
+	mods := copyModifiers(modifiers)
+	mods["sort"] = e.modSort
+	mods["groupby"] = e.modGroupBy
+	return mods
+}
+
+func copyModifiers(src map[string]func(json, arg string) string,
+) map[string]func(json, arg string) string {
+	dst := make(map[string]func(json, arg string) string, len(src)+1)
+	for name, fn := range src {
+		dst[name] = fn
+	}
+	return dst
+}
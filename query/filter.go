@@ -0,0 +1,464 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// maxQueryDepth limits the nesting of parentheses and negations in a
+// filter expression.
+const maxQueryDepth = 32
+
+// queryExpr is a boolean filter expression of a '#(...)' query, such as:
+//
+//	#(age>40 && (last=="Murphy" || nets.#(=="fb")))
+//	#(!(age is number) || start<$end)
+//	#(first in ["Dale","Jane"])
+//
+// Each comparison works like a simple '#(path op value)' query on the
+// current element. A value written as $path or @.path is a sub path of the
+// element, such as #(start<$end); any other unquoted value is a literal.
+// An unquoted value keeps the bare words that follow '&&' or '||', like
+// #(first==a||b) did before they were operators.
+type queryExpr struct {
+	kind  byte // '&', '|', '!' or 0 for a comparison
+	x, y  *queryExpr
+	path  string   // sub path of the element that is compared
+	op    string   // comparison operator, "is", "in" or "" for existence
+	value string   // literal value, or the type name of "is"
+	ref   string   // sub path that is compared to path, from $path or @.path
+	list  []Result // the values of "in"
+}
+
+// queryTypes are the type names accepted by the "is" predicate.
+var queryTypes = map[string]bool{
+	"null": true, "true": true, "false": true, "bool": true, "boolean": true,
+	"number": true, "string": true, "object": true, "array": true,
+}
+
+type queryParser struct {
+	s       string
+	i       int
+	depth   int
+	complex bool
+	syntax  bool // the error is a syntax error, not a regular expression
+}
+
+// maxCachedQueryExprs limits the number of parsed expressions that are
+// kept, like maxCachedRegexps.
+const maxCachedQueryExprs = 1024
+
+// queryExprCache holds the parsed '#(...)' expressions by their text, so
+// the paths that are not compiled do not parse them on every Get.
+var queryExprCache struct {
+	sync.RWMutex
+	m map[string]queryExprEntry
+}
+
+type queryExprEntry struct {
+	x       *queryExpr
+	complex bool
+	err     error
+}
+
+// parseQueryExpr parses the contents of a '#(...)' query. The complex
+// result is false when the query only uses the simple 'path op value'
+// form, which is evaluated by queryMatches instead. A query with a syntax
+// error keeps its simple form when it has one, op is its operator.
+func parseQueryExpr(s, op string) (x *queryExpr, complex bool, err error) {
+	queryExprCache.RLock()
+	ent, ok := queryExprCache.m[s]
+	queryExprCache.RUnlock()
+	if ok {
+		return ent.x, ent.complex, ent.err
+	}
+	var syntax bool
+	ent.x, ent.complex, syntax, ent.err = parseQueryExprText(s)
+	if syntax && op != "" {
+		ent = queryExprEntry{}
+	}
+	queryExprCache.Lock()
+	if queryExprCache.m == nil || len(queryExprCache.m) >= maxCachedQueryExprs {
+		queryExprCache.m = make(map[string]queryExprEntry)
+	}
+	queryExprCache.m[s] = ent
+	queryExprCache.Unlock()
+	return ent.x, ent.complex, ent.err
+}
+
+func parseQueryExprText(s string) (x *queryExpr, complex, syntax bool, err error) {
+	p := queryParser{s: s}
+	x, err = p.or()
+	if err == nil {
+		p.ws()
+		if p.i < len(p.s) {
+			err = p.errorf("unexpected %q", p.s[p.i:])
+		}
+	}
+	if err != nil {
+		x = nil
+	}
+	return x, p.complex, p.syntax, err
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	p.syntax = true
+	return fmt.Errorf("%w: filter %q: %s", ErrInvalidPath, p.s,
+		fmt.Sprintf(format, args...))
+}
+
+func (p *queryParser) ws() {
+	for p.i < len(p.s) && p.s[p.i] <= ' ' {
+		p.i++
+	}
+}
+
+func (p *queryParser) peek(tok string) bool {
+	return strings.HasPrefix(p.s[p.i:], tok)
+}
+
+// keyword returns true when the word at i is followed by a space or '['.
+func (p *queryParser) keyword(i int, word string) bool {
+	if !strings.HasPrefix(p.s[i:], word) {
+		return false
+	}
+	j := i + len(word)
+	return j < len(p.s) && (p.s[j] <= ' ' || (word == "in" && p.s[j] == '['))
+}
+
+func (p *queryParser) or() (*queryExpr, error) {
+	x, err := p.and()
+	for err == nil {
+		p.ws()
+		if !p.peek("||") {
+			break
+		}
+		p.complex = true
+		p.i += 2
+		var y *queryExpr
+		if y, err = p.and(); err == nil {
+			x = &queryExpr{kind: '|', x: x, y: y}
+		}
+	}
+	return x, err
+}
+
+func (p *queryParser) and() (*queryExpr, error) {
+	x, err := p.unary()
+	for err == nil {
+		p.ws()
+		if !p.peek("&&") {
+			break
+		}
+		p.complex = true
+		p.i += 2
+		var y *queryExpr
+		if y, err = p.unary(); err == nil {
+			x = &queryExpr{kind: '&', x: x, y: y}
+		}
+	}
+	return x, err
+}
+
+func (p *queryParser) unary() (*queryExpr, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxQueryDepth {
+		return nil, p.errorf("too deeply nested")
+	}
+	p.ws()
+	if p.peek("!") {
+		j := p.i + 1
+		for j < len(p.s) && p.s[j] <= ' ' {
+			j++
+		}
+		if j < len(p.s) && (p.s[j] == '(' || p.s[j] == '!') {
+			p.complex = true
+			p.i = j
+			x, err := p.unary()
+			if err != nil {
+				return nil, err
+			}
+			return &queryExpr{kind: '!', x: x}, nil
+		}
+	}
+	if p.peek("(") {
+		p.complex = true
+		p.i++
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		p.ws()
+		if !p.peek(")") {
+			return nil, p.errorf("missing ')'")
+		}
+		p.i++
+		return x, nil
+	}
+	return p.cmp()
+}
+
+func (p *queryParser) cmp() (*queryExpr, error) {
+	x := &queryExpr{}
+	x.path = trim(p.scan(true))
+	p.ws()
+	switch {
+	case p.keyword(p.i, "is"):
+		p.complex = true
+		p.i += 2
+		p.ws()
+		start := p.i
+		for p.i < len(p.s) && p.s[p.i] >= 'a' && p.s[p.i] <= 'z' {
+			p.i++
+		}
+		x.op, x.value = "is", p.s[start:p.i]
+		if !queryTypes[x.value] {
+			return nil, p.errorf("unknown type %q", x.value)
+		}
+	case p.keyword(p.i, "in"):
+		p.complex = true
+		p.i += 2
+		p.ws()
+		if !p.peek("[") {
+			return nil, p.errorf("'in' expects an array")
+		}
+		raw := squash(p.s[p.i:])
+		if !Valid(raw) {
+			return nil, p.errorf("invalid array %q", raw)
+		}
+		p.i += len(raw)
+		x.op, x.list = "in", Parse(raw).Array()
+	case p.i < len(p.s) && (strings.IndexByte("!=<>%", p.s[p.i]) != -1 ||
+		isRegexOp(p.s[p.i:])):
+		op := p.s[p.i:]
+		switch {
+		case isRegexOp(op):
+			n := strings.IndexByte(op, '=') + 1
+			x.op, p.i = op[:n], p.i+n
+		case strings.HasPrefix(op, "=="):
+			x.op, p.i = "=", p.i+2
+		case strings.HasPrefix(op, "!="), strings.HasPrefix(op, "!%"),
+			strings.HasPrefix(op, "<="), strings.HasPrefix(op, ">="):
+			x.op, p.i = op[:2], p.i+2
+		case op[0] == '!':
+			return nil, p.errorf("unknown operator %q", op)
+		default:
+			x.op, p.i = op[:1], p.i+1
+		}
+		p.ws()
+		switch {
+		case p.peek(`"`):
+			raw, str := tostr(p.s[p.i:])
+			p.i += len(raw)
+			x.value = str
+		case p.peek("$") && p.i+1 < len(p.s) && isRefStart(p.s[p.i+1]):
+			p.complex = true
+			p.i++
+			x.ref = trim(p.scan(false))
+		case p.peek("@.") && p.i+2 < len(p.s) && isRefStart(p.s[p.i+2]):
+			p.complex = true
+			p.i += 2
+			x.ref = trim(p.scan(false))
+		default:
+			x.value = trim(p.scan(false))
+		}
+		if isRegexOp(x.op) && x.ref == "" {
+			if _, err := compileRegex(x.op, x.value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if x.path == "" && x.op == "" {
+		return nil, p.errorf("missing operand")
+	}
+	return x, nil
+}
+
+// isRefStart returns true if the character can start a "$path" reference.
+func isRefStart(c byte) bool {
+	return c == '_' || c == '@' || c == '#' || c == '\\' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// scan reads a path or a bare value up to the next '&&', '||' or ')' at the
+// top level. A path also stops at a comparison operator or at the "is" and
+// "in" keywords.
+func (p *queryParser) scan(path bool) string {
+	start := p.i
+	depth := 0
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		if depth == 0 {
+			if c == ')' {
+				break
+			}
+			if p.peek("&&") || p.peek("||") {
+				if path || !p.bareOperand(p.i+2) {
+					break
+				}
+				p.i += 2
+				continue
+			}
+			if path {
+				if strings.IndexByte("!=<>%", c) != -1 || isRegexOp(p.s[p.i:]) {
+					break
+				}
+				if (c <= ' ' || p.i == start) && p.i < len(p.s) {
+					j := p.i
+					for j < len(p.s) && p.s[j] <= ' ' {
+						j++
+					}
+					if (j > p.i || p.i == start) &&
+						(p.keyword(j, "is") || p.keyword(j, "in")) {
+						break
+					}
+				}
+			}
+		}
+		switch c {
+		case '\\':
+			p.i++
+		case '"':
+			raw, _ := tostr(p.s[p.i:])
+			p.i += len(raw) - 1
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		}
+		p.i++
+	}
+	if p.i > len(p.s) {
+		p.i = len(p.s)
+	}
+	return p.s[start:p.i]
+}
+
+// bareOperand returns true if the operand at i is a single bare word, such
+// as the b of #(first==a||b), which belongs to the unquoted value before it.
+func (p *queryParser) bareOperand(i int) bool {
+	q := queryParser{s: p.s, i: i}
+	q.ws()
+	if q.peek("(") || q.peek("!") {
+		return false
+	}
+	if trim(q.scan(true)) == "" {
+		return false
+	}
+	q.ws()
+	return q.i >= len(q.s) || q.peek(")") || q.peek("&&") || q.peek("||")
+}
+
+// match returns true if the element v matches the expression.
+func (x *queryExpr) match(e *Engine, v Result) bool {
+	switch x.kind {
+	case '&':
+		return x.x.match(e, v) && x.y.match(e, v)
+	case '|':
+		return x.x.match(e, v) || x.y.match(e, v)
+	case '!':
+		return !x.x.match(e, v)
+	}
+	value := queryValue(e, v, x.path)
+	switch x.op {
+	case "is":
+		return isType(value, x.value)
+	case "in":
+		for _, item := range x.list {
+			// 1 is not in ["1"]
+			if item.Type == value.Type &&
+				matchesQuery(e, "=", item.String(), value) {
+				return true
+			}
+		}
+		return false
+	}
+	if x.ref != "" {
+		ref := queryValue(e, v, x.ref)
+		if !ref.Exists() {
+			return false
+		}
+		if value.Type == String && ref.Type == String {
+			return compareStrings(e, x.op, value.Str, ref.Str)
+		}
+		return matchesQuery(e, x.op, ref.String(), value)
+	}
+	return matchesQuery(e, x.op, x.value, value)
+}
+
+// queryValue returns the sub path of the element, or the element itself
+// for an empty path.
+func queryValue(e *Engine, v Result, path string) Result {
+	if path == "" {
+		return v
+	}
+	if v.Type != JSON {
+		return Result{}
+	}
+	return v.getPath(e, path, nil)
+}
+
+func isType(v Result, name string) bool {
+	if !v.Exists() {
+		return false
+	}
+	switch name {
+	case "null":
+		return v.Type == Null
+	case "true":
+		return v.Type == True
+	case "false":
+		return v.Type == False
+	case "bool", "boolean":
+		return v.Type == True || v.Type == False
+	case "number":
+		return v.Type == Number
+	case "string":
+		return v.Type == String
+	case "object":
+		return v.IsObject()
+	case "array":
+		return v.IsArray()
+	}
+	return false
+}
+
+func compareStrings(e *Engine, op, a, b string) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "%":
+		return matchLimit(a, b)
+	case "!%":
+		return !matchLimit(a, b)
+	case "~=", "~*=", "!~=", "!~*=":
+		return matchRegex(e, op, a, b)
+	}
+	return false
+}
+
+// checkRegex reports the invalid regular expressions of the expression.
+func (x *queryExpr) checkRegex(e *Engine) error {
+	if x.kind != 0 {
+		if err := x.x.checkRegex(e); err != nil || x.y == nil {
+			return err
+		}
+		return x.y.checkRegex(e)
+	}
+	if x.ref != "" {
+		return nil
+	}
+	return checkRegex(e, x.op, x.value)
+}
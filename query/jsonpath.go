@@ -0,0 +1,895 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// This file implements the JSONPath query language of RFC 9535. An
+// expression is parsed into a jpQuery, which is evaluated over the Result
+// tree of the json. Child results keep the offsets set by ForEach, so each
+// returned node has its Index populated.
+
+// maxJSONPathInt is the largest index allowed by RFC 9535, 2^53-1.
+const maxJSONPathInt = 1<<53 - 1
+
+// JSONPath 使用RFC 9535定义的JSONPath表达式在json中搜索，返回匹配的所有节点。
+// 支持名称、通配符、索引、切片、递归下降 .. 、过滤器 ?() 以及
+// length、count、match、search、value 函数扩展，例如：
+//
+//	$.store.book[?@.price < 10].title
+//	$..author
+//	$.store.book[-1:]
+//
+// 每个结果都设置了Index，因此可以使用 Result.Path 得到对应的查询路径。
+// 表达式的语法错误会返回ErrInvalidPath。
+func JSONPath(json, expr string) ([]Result, error) {
+	q, err := parseJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	root := jsonPathRoot(json)
+	if !root.Exists() {
+		return nil, nil
+	}
+	return q.eval(root, root), nil
+}
+
+// jsonPathRoot returns the root value of the json, without the trailing
+// space that Parse keeps in the Raw of an object or array.
+func jsonPathRoot(json string) Result {
+	root := Parse(json)
+	if root.Type == JSON {
+		root.Raw = strings.TrimRight(root.Raw, " \t\r\n")
+	}
+	return root
+}
+
+// jpQuery is a JSONPath query. The query starts at the root node '$', or at
+// the current node '@' inside of a filter.
+type jpQuery struct {
+	root bool
+	segs []jpSegment
+}
+
+// jpSegment is a child segment, or a descendant segment '..' when desc is
+// set.
+type jpSegment struct {
+	desc bool
+	sels []jpSelector
+}
+
+// jpSelector is a name, wildcard '*', index, slice or filter '?' selector.
+type jpSelector struct {
+	kind   byte // 'n', '*', 'i', 's' or '?'
+	name   string
+	index  int
+	slice  [3]int
+	bounds [3]bool // whether the start, end and step of a slice are set
+	filter *jpExpr
+}
+
+// jpExpr is a logical expression of a filter selector.
+type jpExpr struct {
+	kind byte // '|', '&', '!', 'c' for a comparison, or 't' for a test
+	x, y *jpExpr
+	op   string
+	l, r *jpOperand // the comparables, or l for a test
+}
+
+// jpOperand is a literal 'l', a filter query 'q' or a function call 'f'.
+type jpOperand struct {
+	kind byte
+	lit  Result
+	q    *jpQuery
+	fn   string
+	args []*jpOperand
+}
+
+// jpValue is a value of a filter expression. Nothing is represented by a
+// false ok.
+type jpValue struct {
+	v  Result
+	ok bool
+}
+
+// jpFuncs maps the function extensions to their parameter types and result
+// type: 'v' for a value, 'n' for nodes and 'l' for a logical.
+var jpFuncs = map[string]struct {
+	params string
+	result byte
+}{
+	"length": {"v", 'v'},
+	"count":  {"n", 'v'},
+	"value":  {"n", 'v'},
+	"match":  {"vv", 'l'},
+	"search": {"vv", 'l'},
+}
+
+// singular returns true if the query selects at most one node.
+func (q *jpQuery) singular() bool {
+	for _, seg := range q.segs {
+		if seg.desc || len(seg.sels) != 1 ||
+			(seg.sels[0].kind != 'n' && seg.sels[0].kind != 'i') {
+			return false
+		}
+	}
+	return true
+}
+
+// eval returns the nodes selected by the query.
+func (q *jpQuery) eval(root, cur Result) []Result {
+	nodes := []Result{cur}
+	if q.root {
+		nodes[0] = root
+	}
+	for _, seg := range q.segs {
+		var out []Result
+		for _, n := range nodes {
+			if seg.desc {
+				jpDescend(n, func(d Result) {
+					out = seg.apply(root, d, out)
+				})
+			} else {
+				out = seg.apply(root, n, out)
+			}
+		}
+		nodes = out
+		if len(nodes) == 0 {
+			break
+		}
+	}
+	return nodes
+}
+
+// jpDescend calls fn for the node and all of its descendants, in document
+// order.
+func jpDescend(n Result, fn func(Result)) {
+	fn(n)
+	if n.IsArray() || n.IsObject() {
+		n.ForEach(func(_, value Result) bool {
+			jpDescend(value, fn)
+			return true
+		})
+	}
+}
+
+func (seg *jpSegment) apply(root, n Result, out []Result) []Result {
+	for i := range seg.sels {
+		out = seg.sels[i].apply(root, n, out)
+	}
+	return out
+}
+
+func (sel *jpSelector) apply(root, n Result, out []Result) []Result {
+	switch sel.kind {
+	case 'n':
+		if n.IsObject() {
+			n.ForEach(func(key, value Result) bool {
+				if key.Str == sel.name {
+					out = append(out, value)
+					return false
+				}
+				return true
+			})
+		}
+	case '*':
+		if n.IsArray() || n.IsObject() {
+			n.ForEach(func(_, value Result) bool {
+				out = append(out, value)
+				return true
+			})
+		}
+	case '?':
+		if n.IsArray() || n.IsObject() {
+			n.ForEach(func(_, value Result) bool {
+				if sel.filter.test(root, value) {
+					out = append(out, value)
+				}
+				return true
+			})
+		}
+	case 'i':
+		if n.IsArray() {
+			elems := n.Array()
+			i := sel.index
+			if i < 0 {
+				i += len(elems)
+			}
+			if i >= 0 && i < len(elems) {
+				out = append(out, elems[i])
+			}
+		}
+	case 's':
+		if n.IsArray() {
+			elems := n.Array()
+			for _, i := range sel.sliceIndexes(len(elems)) {
+				out = append(out, elems[i])
+			}
+		}
+	}
+	return out
+}
+
+// sliceIndexes returns the selected indexes of an array with n elements,
+// following the slice semantics of RFC 9535.
+func (sel *jpSelector) sliceIndexes(n int) []int {
+	return sliceIndexes(n, sel.slice, sel.bounds)
+}
+
+// test evaluates the logical expression for the current node.
+func (x *jpExpr) test(root, cur Result) bool {
+	switch x.kind {
+	case '|':
+		return x.x.test(root, cur) || x.y.test(root, cur)
+	case '&':
+		return x.x.test(root, cur) && x.y.test(root, cur)
+	case '!':
+		return !x.x.test(root, cur)
+	case 'c':
+		return jpCompare(x.op, x.l.value(root, cur), x.r.value(root, cur))
+	}
+	if x.l.kind == 'f' {
+		return x.l.logical(root, cur)
+	}
+	return len(x.l.q.eval(root, cur)) > 0
+}
+
+// value returns the value of a comparable, or of a function argument.
+func (o *jpOperand) value(root, cur Result) jpValue {
+	switch o.kind {
+	case 'l':
+		return jpValue{o.lit, true}
+	case 'q':
+		if nodes := o.q.eval(root, cur); len(nodes) == 1 {
+			return jpValue{nodes[0], true}
+		}
+		return jpValue{}
+	}
+	switch o.fn {
+	case "length":
+		arg := o.args[0].value(root, cur)
+		if !arg.ok {
+			return jpValue{}
+		}
+		var n int
+		switch {
+		case arg.v.Type == String:
+			n = utf8.RuneCountInString(arg.v.Str)
+		case arg.v.IsArray() || arg.v.IsObject():
+			arg.v.ForEach(func(_, _ Result) bool {
+				n++
+				return true
+			})
+		default:
+			return jpValue{}
+		}
+		return jpValue{jpNumber(n), true}
+	case "count":
+		return jpValue{jpNumber(len(o.args[0].q.eval(root, cur))), true}
+	case "value":
+		return o.args[0].value(root, cur)
+	}
+	return jpValue{}
+}
+
+// logical returns the result of a function with a logical result.
+func (o *jpOperand) logical(root, cur Result) bool {
+	str, pattern := o.args[0].value(root, cur), o.args[1].value(root, cur)
+	if !str.ok || !pattern.ok || str.v.Type != String ||
+		pattern.v.Type != String {
+		return false
+	}
+	expr := pattern.v.Str
+	if o.fn == "match" {
+		expr = "^(?:" + expr + ")$"
+	}
+	re, err := compileRegex("~=", expr)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(str.v.Str)
+}
+
+func jpNumber(n int) Result {
+	return Result{Type: Number, Num: float64(n), Raw: strconv.Itoa(n)}
+}
+
+// jpCompare compares two values as defined by RFC 9535. Nothing is only
+// equal to Nothing, and only numbers and strings are ordered.
+func jpCompare(op string, a, b jpValue) bool {
+	switch op {
+	case "==":
+		return jpEqual(a, b)
+	case "!=":
+		return !jpEqual(a, b)
+	case "<":
+		return jpLess(a, b)
+	case "<=":
+		return jpLess(a, b) || jpEqual(a, b)
+	case ">":
+		return jpLess(b, a)
+	case ">=":
+		return jpLess(b, a) || jpEqual(a, b)
+	}
+	return false
+}
+
+func jpEqual(a, b jpValue) bool {
+	if !a.ok || !b.ok {
+		return a.ok == b.ok
+	}
+	return jsonEqual(a.v, b.v)
+}
+
+func jpLess(a, b jpValue) bool {
+	if !a.ok || !b.ok || a.v.Type != b.v.Type {
+		return false
+	}
+	switch a.v.Type {
+	case Number:
+		return compareValues(a.v, b.v) < 0
+	case String:
+		return a.v.Str < b.v.Str
+	}
+	return false
+}
+
+// jsonEqual returns true if two json values are equal. Numbers are compared
+// by value, and objects regardless of the order of their members.
+func jsonEqual(a, b Result) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case Number:
+		return compareValues(a, b) == 0
+	case String:
+		return a.Str == b.Str
+	case JSON:
+	default:
+		return true
+	}
+	switch {
+	case a.IsArray() && b.IsArray():
+		ae, be := a.Array(), b.Array()
+		if len(ae) != len(be) {
+			return false
+		}
+		for i := range ae {
+			if !jsonEqual(ae[i], be[i]) {
+				return false
+			}
+		}
+		return true
+	case a.IsObject() && b.IsObject():
+		am, bm := a.Map(), b.Map()
+		if len(am) != len(bm) {
+			return false
+		}
+		for k, av := range am {
+			bv, ok := bm[k]
+			if !ok || !jsonEqual(av, bv) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// jpParser parses a JSONPath expression.
+type jpParser struct {
+	s string
+	i int
+}
+
+func parseJSONPath(expr string) (*jpQuery, error) {
+	p := jpParser{s: expr}
+	if !p.peek("$") {
+		return nil, p.errorf("expected '$'")
+	}
+	p.i++
+	q, err := p.segments(true)
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.i:])
+	}
+	return q, nil
+}
+
+func (p *jpParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: jsonpath %q at offset %d: %s", ErrInvalidPath,
+		p.s, p.i, fmt.Sprintf(format, args...))
+}
+
+func (p *jpParser) peek(tok string) bool {
+	return strings.HasPrefix(p.s[p.i:], tok)
+}
+
+// ws skips the blank space allowed by RFC 9535.
+func (p *jpParser) ws() {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t' ||
+		p.s[p.i] == '\n' || p.s[p.i] == '\r') {
+		p.i++
+	}
+}
+
+func (p *jpParser) segments(root bool) (*jpQuery, error) {
+	q := &jpQuery{root: root}
+	for {
+		save := p.i
+		p.ws()
+		var seg jpSegment
+		switch {
+		case p.peek(".."):
+			p.i += 2
+			seg.desc = true
+			if p.peek("[") {
+				sels, err := p.bracketed()
+				if err != nil {
+					return nil, err
+				}
+				seg.sels = sels
+			} else if sel, ok := p.shorthand(); ok {
+				seg.sels = []jpSelector{sel}
+			} else {
+				return nil, p.errorf("expected a selector after '..'")
+			}
+		case p.peek("."):
+			p.i++
+			sel, ok := p.shorthand()
+			if !ok {
+				return nil, p.errorf("expected a name or '*' after '.'")
+			}
+			seg.sels = []jpSelector{sel}
+		case p.peek("["):
+			sels, err := p.bracketed()
+			if err != nil {
+				return nil, err
+			}
+			seg.sels = sels
+		default:
+			p.i = save
+			return q, nil
+		}
+		q.segs = append(q.segs, seg)
+	}
+}
+
+// shorthand parses the '*' or member name that follows a '.'.
+func (p *jpParser) shorthand() (jpSelector, bool) {
+	if p.peek("*") {
+		p.i++
+		return jpSelector{kind: '*'}, true
+	}
+	start := p.i
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		if c == '_' || c >= 0x80 || (c >= 'a' && c <= 'z') ||
+			(c >= 'A' && c <= 'Z') || (p.i > start && c >= '0' && c <= '9') {
+			p.i++
+			continue
+		}
+		break
+	}
+	if p.i == start {
+		return jpSelector{}, false
+	}
+	return jpSelector{kind: 'n', name: p.s[start:p.i]}, true
+}
+
+func (p *jpParser) bracketed() ([]jpSelector, error) {
+	p.i++ // '['
+	var sels []jpSelector
+	for {
+		p.ws()
+		sel, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+		p.ws()
+		if p.peek(",") {
+			p.i++
+			continue
+		}
+		if p.peek("]") {
+			p.i++
+			return sels, nil
+		}
+		return nil, p.errorf("expected ',' or ']'")
+	}
+}
+
+func (p *jpParser) selector() (jpSelector, error) {
+	switch {
+	case p.peek("'"), p.peek(`"`):
+		name, err := p.str()
+		return jpSelector{kind: 'n', name: name}, err
+	case p.peek("*"):
+		p.i++
+		return jpSelector{kind: '*'}, nil
+	case p.peek("?"):
+		p.i++
+		p.ws()
+		x, err := p.or()
+		return jpSelector{kind: '?', filter: x}, err
+	}
+	var sel jpSelector
+	for part := 0; part < 3; part++ {
+		if part > 0 {
+			if !p.peek(":") {
+				break
+			}
+			p.i++
+			p.ws()
+		}
+		if p.peek("-") || (p.i < len(p.s) && p.s[p.i] >= '0' && p.s[p.i] <= '9') {
+			n, err := p.integer()
+			if err != nil {
+				return sel, err
+			}
+			sel.slice[part], sel.bounds[part] = n, true
+			p.ws()
+		}
+		if part == 0 && !p.peek(":") {
+			if !sel.bounds[0] {
+				return sel, p.errorf("invalid selector")
+			}
+			sel.kind, sel.index = 'i', sel.slice[0]
+			return sel, nil
+		}
+	}
+	sel.kind = 's'
+	return sel, nil
+}
+
+// integer parses an index or slice bound in the I-JSON range.
+func (p *jpParser) integer() (int, error) {
+	start := p.i
+	if p.peek("-") {
+		p.i++
+	}
+	ds := p.i
+	for p.i < len(p.s) && p.s[p.i] >= '0' && p.s[p.i] <= '9' {
+		p.i++
+	}
+	digits := p.s[ds:p.i]
+	switch {
+	case digits == "":
+		return 0, p.errorf("expected an integer")
+	case digits[0] == '0' && (len(digits) > 1 || ds > start):
+		return 0, p.errorf("invalid integer %q", p.s[start:p.i])
+	}
+	n, err := strconv.ParseInt(p.s[start:p.i], 10, 64)
+	if err != nil || n > maxJSONPathInt || n < -maxJSONPathInt {
+		return 0, p.errorf("integer %q out of range", p.s[start:p.i])
+	}
+	return int(n), nil
+}
+
+// str parses a single or double quoted string literal.
+func (p *jpParser) str() (string, error) {
+	quote := p.s[p.i]
+	p.i++
+	var b []byte
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		switch {
+		case c == quote:
+			p.i++
+			return string(b), nil
+		case c < 0x20:
+			return "", p.errorf("control character in string")
+		case c != '\\':
+			b = append(b, c)
+			p.i++
+			continue
+		}
+		p.i++
+		if p.i >= len(p.s) {
+			break
+		}
+		c = p.s[p.i]
+		p.i++
+		switch c {
+		case 'b':
+			b = append(b, '\b')
+		case 'f':
+			b = append(b, '\f')
+		case 'n':
+			b = append(b, '\n')
+		case 'r':
+			b = append(b, '\r')
+		case 't':
+			b = append(b, '\t')
+		case '/', '\\', quote:
+			b = append(b, c)
+		case 'u':
+			r, err := p.hex4()
+			if err != nil {
+				return "", err
+			}
+			if utf16.IsSurrogate(r) {
+				if r >= 0xDC00 || !p.peek(`\u`) {
+					return "", p.errorf("lone surrogate in string")
+				}
+				p.i += 2
+				r2, err := p.hex4()
+				if err != nil {
+					return "", err
+				}
+				if r = utf16.DecodeRune(r, r2); r == utf8.RuneError {
+					return "", p.errorf("invalid surrogate pair in string")
+				}
+			}
+			b = append(b, string(r)...)
+		default:
+			return "", p.errorf("invalid escape '\\%c'", c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *jpParser) hex4() (rune, error) {
+	if p.i+4 > len(p.s) {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	n, err := strconv.ParseUint(p.s[p.i:p.i+4], 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	p.i += 4
+	return rune(n), nil
+}
+
+func (p *jpParser) or() (*jpExpr, error) {
+	x, err := p.and()
+	for err == nil {
+		save := p.i
+		p.ws()
+		if !p.peek("||") {
+			p.i = save
+			break
+		}
+		p.i += 2
+		p.ws()
+		var y *jpExpr
+		if y, err = p.and(); err == nil {
+			x = &jpExpr{kind: '|', x: x, y: y}
+		}
+	}
+	return x, err
+}
+
+func (p *jpParser) and() (*jpExpr, error) {
+	x, err := p.basic()
+	for err == nil {
+		save := p.i
+		p.ws()
+		if !p.peek("&&") {
+			p.i = save
+			break
+		}
+		p.i += 2
+		p.ws()
+		var y *jpExpr
+		if y, err = p.basic(); err == nil {
+			x = &jpExpr{kind: '&', x: x, y: y}
+		}
+	}
+	return x, err
+}
+
+func (p *jpParser) basic() (*jpExpr, error) {
+	not := false
+	if p.peek("!") {
+		p.i++
+		p.ws()
+		not = true
+	}
+	var x *jpExpr
+	if p.peek("(") {
+		p.i++
+		p.ws()
+		var err error
+		if x, err = p.or(); err != nil {
+			return nil, err
+		}
+		p.ws()
+		if !p.peek(")") {
+			return nil, p.errorf("expected ')'")
+		}
+		p.i++
+	} else {
+		l, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		save := p.i
+		p.ws()
+		if op := p.compareOp(); op != "" && !not {
+			p.ws()
+			r, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.comparable(l); err != nil {
+				return nil, err
+			}
+			if err := p.comparable(r); err != nil {
+				return nil, err
+			}
+			return &jpExpr{kind: 'c', op: op, l: l, r: r}, nil
+		}
+		p.i = save
+		switch {
+		case l.kind == 'q':
+		case l.kind == 'f' && jpFuncs[l.fn].result == 'l':
+		default:
+			return nil, p.errorf("expected a query or a logical function")
+		}
+		x = &jpExpr{kind: 't', l: l}
+	}
+	if not {
+		x = &jpExpr{kind: '!', x: x}
+	}
+	return x, nil
+}
+
+func (p *jpParser) compareOp() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.peek(op) {
+			p.i += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// comparable checks that the operand can be compared: a literal, a
+// singular query or a function with a value result.
+func (p *jpParser) comparable(o *jpOperand) error {
+	switch o.kind {
+	case 'q':
+		if !o.q.singular() {
+			return p.errorf("non-singular query in comparison")
+		}
+	case 'f':
+		if jpFuncs[o.fn].result != 'v' {
+			return p.errorf("function %s() cannot be compared", o.fn)
+		}
+	}
+	return nil
+}
+
+func (p *jpParser) operand() (*jpOperand, error) {
+	if p.i >= len(p.s) {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	c := p.s[p.i]
+	switch {
+	case c == '@' || c == '$':
+		p.i++
+		q, err := p.segments(c == '$')
+		if err != nil {
+			return nil, err
+		}
+		return &jpOperand{kind: 'q', q: q}, nil
+	case c == '\'' || c == '"':
+		s, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		lit := Result{Type: String, Str: s,
+			Raw: bytesString(appendJSONString(nil, s))}
+		return &jpOperand{kind: 'l', lit: lit}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.number()
+	case c >= 'a' && c <= 'z':
+		start := p.i
+		for p.i < len(p.s) && (p.s[p.i] == '_' ||
+			(p.s[p.i] >= 'a' && p.s[p.i] <= 'z') ||
+			(p.s[p.i] >= '0' && p.s[p.i] <= '9')) {
+			p.i++
+		}
+		name := p.s[start:p.i]
+		if p.peek("(") {
+			return p.function(name)
+		}
+		switch name {
+		case "true", "false", "null":
+			return &jpOperand{kind: 'l', lit: Parse(name)}, nil
+		}
+		p.i = start
+	}
+	return nil, p.errorf("unexpected %q", p.s[p.i:])
+}
+
+// number parses a number literal, using the json number grammar.
+func (p *jpParser) number() (*jpOperand, error) {
+	start := p.i
+	if p.peek("-") {
+		p.i++
+	}
+	digits := func() int {
+		ds := p.i
+		for p.i < len(p.s) && p.s[p.i] >= '0' && p.s[p.i] <= '9' {
+			p.i++
+		}
+		return p.i - ds
+	}
+	is := p.i
+	if n := digits(); n == 0 || (n > 1 && p.s[is] == '0') {
+		return nil, p.errorf("invalid number")
+	}
+	if p.peek(".") {
+		p.i++
+		if digits() == 0 {
+			return nil, p.errorf("invalid number")
+		}
+	}
+	if p.peek("e") || p.peek("E") {
+		p.i++
+		if p.peek("+") || p.peek("-") {
+			p.i++
+		}
+		if digits() == 0 {
+			return nil, p.errorf("invalid number")
+		}
+	}
+	return &jpOperand{kind: 'l', lit: Parse(p.s[start:p.i])}, nil
+}
+
+// function parses the arguments of a function extension and checks their
+// types.
+func (p *jpParser) function(name string) (*jpOperand, error) {
+	f, ok := jpFuncs[name]
+	if !ok {
+		return nil, p.errorf("unknown function %s()", name)
+	}
+	p.i++ // '('
+	o := &jpOperand{kind: 'f', fn: name}
+	for {
+		p.ws()
+		if len(o.args) == 0 && p.peek(")") {
+			break
+		}
+		arg, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		o.args = append(o.args, arg)
+		p.ws()
+		if !p.peek(",") {
+			break
+		}
+		p.i++
+	}
+	if !p.peek(")") {
+		return nil, p.errorf("expected ')'")
+	}
+	p.i++
+	if len(o.args) != len(f.params) {
+		return nil, p.errorf("%s() takes %d arguments", name, len(f.params))
+	}
+	for i, arg := range o.args {
+		switch f.params[i] {
+		case 'v':
+			if err := p.comparable(arg); err != nil {
+				return nil, err
+			}
+		case 'n':
+			if arg.kind != 'q' {
+				return nil, p.errorf("%s() expects a query", name)
+			}
+		}
+	}
+	return o, nil
+}
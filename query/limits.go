@@ -0,0 +1,169 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrLimitExceeded 当json超过引擎选项中的大小限制时返回。
+// 超过限制时引擎的 Get、GetBytes、GetMany 以及编译后路径的查询结果不存在，
+// 需要区分超过限制和值不存在时请使用 GetE 等返回错误的方法。
+var ErrLimitExceeded = errors.New("query: limit exceeded")
+
+// LimitError 表示json超过了引擎选项中的一个大小限制，它包装了 ErrLimitExceeded。
+type LimitError struct {
+	Limit  string // 超过的选项，例如 "MaxDepth"
+	Max    int    // 选项的值
+	Offset int    // 超过限制处的字节偏移量
+}
+
+func (err *LimitError) Error() string {
+	return fmt.Sprintf("%v: %s %d exceeded at offset %d",
+		ErrLimitExceeded, err.Limit, err.Max, err.Offset)
+}
+
+func (err *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// hasLimits returns true if any of the size limits is set.
+func (o *Options) hasLimits() bool {
+	return o.MaxDepth > 0 || o.MaxBytes > 0 || o.MaxStringLength > 0 ||
+		o.MaxNumberLength > 0 || o.MaxArrayElements > 0 || o.MaxObjectKeys > 0
+}
+
+// CheckLimits 检查json是否超过引擎选项中的大小限制，返回的错误是 *LimitError，
+// 指出超过的限制和所在的字节偏移量。字符串长度按反转义后的字节计算，
+// 与 jsoniter.Config 的 MaxStringLength 相同。未设置限制时总是返回nil。
+func (e *Engine) CheckLimits(json string) error {
+	return e.checkLimits(json)
+}
+
+// checkLimits scans the whole json once. The scan does not validate the
+// json, it only measures the nesting, the members and the tokens.
+func (e *Engine) checkLimits(json string) error {
+	o := &e.opts
+	if !o.hasLimits() {
+		return nil
+	}
+	exceeded := func(limit string, max, i int) error {
+		return &LimitError{Limit: limit, Max: max, Offset: i}
+	}
+	if o.MaxBytes > 0 && len(json) > o.MaxBytes {
+		return exceeded("MaxBytes", o.MaxBytes, o.MaxBytes)
+	}
+	type container struct {
+		array bool
+		n     int
+	}
+	var stack []container
+	// fresh is true until the first member of a new container is seen
+	fresh := false
+	member := func(i int) error {
+		top := &stack[len(stack)-1]
+		top.n++
+		switch {
+		case top.array && o.MaxArrayElements > 0 && top.n > o.MaxArrayElements:
+			return exceeded("MaxArrayElements", o.MaxArrayElements, i)
+		case !top.array && o.MaxObjectKeys > 0 && top.n > o.MaxObjectKeys:
+			return exceeded("MaxObjectKeys", o.MaxObjectKeys, i)
+		}
+		return nil
+	}
+	for i := 0; i < len(json); i++ {
+		c := json[i]
+		if c <= ' ' {
+			continue
+		}
+		if fresh {
+			fresh = false
+			if c != ']' && c != '}' {
+				if err := member(i); err != nil {
+					return err
+				}
+			}
+		}
+		switch c {
+		case '{', '[':
+			stack = append(stack, container{array: c == '['})
+			if o.MaxDepth > 0 && len(stack) > o.MaxDepth {
+				return exceeded("MaxDepth", o.MaxDepth, i)
+			}
+			fresh = true
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case ',':
+			if len(stack) > 0 {
+				if err := member(i); err != nil {
+					return err
+				}
+			}
+		case '"':
+			start := i
+			for i++; i < len(json) && json[i] != '"'; i++ {
+				if json[i] == '\\' {
+					i++
+				}
+			}
+			// unescaping never makes a string longer, so only the strings
+			// whose raw bytes exceed the limit are measured
+			if o.MaxStringLength > 0 && i-start-1 > o.MaxStringLength &&
+				unescapedLen(json[start+1:i]) > o.MaxStringLength {
+				return exceeded("MaxStringLength", o.MaxStringLength, start)
+			}
+		case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			start := i
+			for ; i+1 < len(json); i++ {
+				c := json[i+1]
+				if (c < '0' || c > '9') && c != '.' && c != 'e' && c != 'E' &&
+					c != '+' && c != '-' {
+					break
+				}
+			}
+			// the sign is not counted, as in jsoniter
+			n := i - start + 1
+			if c == '-' {
+				n--
+			}
+			if o.MaxNumberLength > 0 && n > o.MaxNumberLength {
+				return exceeded("MaxNumberLength", o.MaxNumberLength, start)
+			}
+		}
+	}
+	return nil
+}
+
+// unescapedLen returns the bytes of the json string s after unescaping. It
+// counts like jsoniter, a lone surrogate is a U+FFFD of 3 bytes.
+func unescapedLen(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			n++
+			continue
+		}
+		i++
+		if s[i] != 'u' || i+5 > len(s) {
+			n++
+			continue
+		}
+		r := runeit(s[i+1:])
+		i += 4
+		if utf16.IsSurrogate(r) && i+7 <= len(s) && s[i+1] == '\\' && s[i+2] == 'u' {
+			if r2 := utf16.DecodeRune(r, runeit(s[i+3:])); r2 != utf8.RuneError {
+				r = r2
+				i += 6
+			}
+		}
+		if utf16.IsSurrogate(r) {
+			n += 3
+		} else {
+			n += utf8.RuneLen(r)
+		}
+	}
+	return n
+}
@@ -0,0 +1,512 @@
+package query
+
+import (
+	"strconv"
+)
+
+// MultiPath 一组预先编译好的查询路径。
+// 路径被合并成一棵前缀树，查询时只遍历一次json，就能得到所有路径的结果，
+// 共享前缀的路径和 # 数组投影只会被扫描一次。
+// 包含修饰符、过滤器、子选择器或管道的路径会退回到逐个查询。
+// MultiPath 可以被多个goroutine并发复用。
+type MultiPath struct {
+	e        *Engine
+	paths    []string
+	prog     mpProgram
+	fallback []*Path // compiled paths that the trie cannot evaluate
+	plain    []bool  // paths that are evaluated by the trie
+}
+
+// mpProgram is a trie of path components. Each path added to the program
+// owns a result slot in the frames that are evaluated against it.
+type mpProgram struct {
+	root *mpNode
+}
+
+// mpNode is a node of the trie. The same node is used for objects and
+// arrays, the json value decides which edges are followed.
+type mpNode struct {
+	keys    map[string]*mpNode
+	wild    []mpWild
+	idx     map[int][]*mpNode
+	targets []int   // slots that receive this value
+	count   []int   // slots that receive the length of this array
+	proj    *mpProj // '#.path' projections over the elements of this array
+}
+
+type mpWild struct {
+	pattern string
+	node    *mpNode
+}
+
+// mpProj evaluates the remaining paths of '#.path' projections against
+// each array element. Sub slot k is appended to the parent slot slots[k].
+type mpProj struct {
+	prog  mpProgram
+	slots []int
+}
+
+// mpPart is a single component of a path that the trie can evaluate.
+type mpPart struct {
+	key  string // object key, unescaped
+	wild bool   // key contains '*' or '?'
+	idx  int    // array index or -1
+	hash bool   // the component is '#'
+}
+
+// CompileMany 编译一组查询路径，用于在一次遍历中提取多个值。
+// 任何一个路径的语法错误都会返回错误。
+func CompileMany(paths ...string) (*MultiPath, error) {
+	return defaultEngine.CompileMany(paths...)
+}
+
+// CompileMany 使用该引擎编译一组查询路径。
+func (e *Engine) CompileMany(paths ...string) (*MultiPath, error) {
+	m := newMultiPath(e, paths)
+	for i, path := range paths {
+		p, err := e.Compile(path)
+		if err != nil {
+			return nil, err
+		}
+		if !m.plain[i] {
+			m.fallback[i] = p
+		}
+	}
+	return m, nil
+}
+
+// MustCompileMany 与CompileMany相同，但路径无效时会panic。
+func MustCompileMany(paths ...string) *MultiPath {
+	m, err := CompileMany(paths...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func newMultiPath(e *Engine, paths []string) *MultiPath {
+	m := &MultiPath{
+		e:        e,
+		paths:    paths,
+		prog:     mpProgram{root: &mpNode{}},
+		fallback: make([]*Path, len(paths)),
+		plain:    make([]bool, len(paths)),
+	}
+	for i, path := range paths {
+		parts, ok := splitSimplePath(e, path)
+		if !ok {
+			continue
+		}
+		m.plain[i] = true
+		m.prog.root.add(parts, i)
+	}
+	return m
+}
+
+// Get 在json中一次性搜索所有路径，返回的结果与路径一一对应。
+func (m *MultiPath) Get(json string) []Result {
+	res := make([]Result, len(m.paths))
+	m.get(json, res)
+	return res
+}
+
+// GetBytes 在json中一次性搜索所有路径。
+// 如果使用字节，此方法优于Get(string(data))
+func (m *MultiPath) GetBytes(json []byte) []Result {
+	res := make([]Result, len(m.paths))
+	if json != nil {
+		m.get(bytesString(json), res)
+		for i := range res {
+			res[i] = copyResult(res[i])
+		}
+	}
+	return res
+}
+
+// GetE 与 Get 相同，但json超过引擎的限制时返回 *LimitError。
+func (m *MultiPath) GetE(json string) ([]Result, error) {
+	res := make([]Result, len(m.paths))
+	if err := m.get(json, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (m *MultiPath) get(json string, res []Result) error {
+	if err := m.e.checkLimits(json); err != nil {
+		return err
+	}
+	r := mpRunner{json: json}
+	frame := mpFrame{res: res}
+	for i := 0; i < len(json); i++ {
+		if json[i] == '{' || json[i] == '[' {
+			r.value(i, []mpAct{{m.prog.root, &frame}})
+			break
+		}
+	}
+	for i, path := range m.paths {
+		if !m.plain[i] {
+			res[i] = getPath(m.e, json, path, m.fallback[i])
+		}
+	}
+	return nil
+}
+
+// splitSimplePath splits a path into components that the trie can
+// evaluate. It returns false for paths that use modifiers, static values,
+// selectors, queries, pipes or json lines.
+func splitSimplePath(e *Engine, path string) (parts []mpPart, ok bool) {
+	if path == "" {
+		return nil, false
+	}
+	switch path[0] {
+	case '@', '!', '[', '{', '.':
+		return nil, false
+	}
+	if parseDeepPath(path) != nil {
+		return nil, false
+	}
+	for {
+		rp := parseObjectPath(e, path)
+		ra := parseArrayPath(e, path)
+		if rp.piped || ra.piped || ra.query.on || rp.part == "" ||
+			(rp.more && rp.path == "") {
+			return nil, false
+		}
+		if _, ok := parseSlice(rp.part); ok {
+			return nil, false
+		}
+		part := mpPart{key: rp.part, wild: rp.wild, idx: -1}
+		if ra.more == rp.more && ra.path == rp.path {
+			// the array view only agrees with the object view when the
+			// component has no escaped characters.
+			if ra.part == "#" {
+				part.hash = true
+			} else if n, ok := parseUint(ra.part); ok {
+				part.idx = int(n)
+			}
+		}
+		parts = append(parts, part)
+		if !rp.more {
+			return parts, true
+		}
+		path = rp.path
+	}
+}
+
+// add adds the path components to the trie with the result slot.
+func (n *mpNode) add(parts []mpPart, slot int) {
+	if len(parts) == 0 {
+		n.targets = append(n.targets, slot)
+		return
+	}
+	p := parts[0]
+	if p.hash {
+		if len(parts) == 1 {
+			n.count = append(n.count, slot)
+		} else {
+			if n.proj == nil {
+				n.proj = &mpProj{prog: mpProgram{root: &mpNode{}}}
+			}
+			sub := len(n.proj.slots)
+			n.proj.slots = append(n.proj.slots, slot)
+			n.proj.prog.root.add(parts[1:], sub)
+		}
+	}
+	var child *mpNode
+	if p.wild {
+		for _, w := range n.wild {
+			if w.pattern == p.key {
+				child = w.node
+				break
+			}
+		}
+		if child == nil {
+			child = &mpNode{}
+			n.wild = append(n.wild, mpWild{pattern: p.key, node: child})
+		}
+	} else {
+		child = n.keys[p.key]
+		if child == nil {
+			if n.keys == nil {
+				n.keys = make(map[string]*mpNode)
+			}
+			child = &mpNode{}
+			n.keys[p.key] = child
+		}
+	}
+	if p.idx >= 0 {
+		if n.idx == nil {
+			n.idx = make(map[int][]*mpNode)
+		}
+		var found bool
+		for _, c := range n.idx[p.idx] {
+			found = found || c == child
+		}
+		if !found {
+			n.idx[p.idx] = append(n.idx[p.idx], child)
+		}
+	}
+	child.add(parts[1:], slot)
+}
+
+// descends returns true when the node needs the contents of its value.
+func (n *mpNode) descends() bool {
+	return len(n.keys) > 0 || len(n.wild) > 0 || len(n.idx) > 0 ||
+		len(n.count) > 0 || n.proj != nil
+}
+
+// mpFrame holds the results of one evaluation of a program.
+type mpFrame struct {
+	res []Result
+}
+
+// mpAct is a trie node that is active for the current value.
+type mpAct struct {
+	n *mpNode
+	f *mpFrame
+}
+
+// mpRunner walks the json once, following all active nodes at the same
+// time.
+type mpRunner struct {
+	json  string
+	stack []mpAct
+}
+
+// value walks the value starting at json[i] and returns the index after it.
+func (r *mpRunner) value(i int, acts []mpAct) int {
+	json := r.json
+	start := i
+	var descend bool
+	for _, a := range acts {
+		if a.n.descends() {
+			descend = true
+			break
+		}
+	}
+	var vesc bool
+	switch json[i] {
+	case '{':
+		if descend {
+			i = r.object(i+1, acts)
+		} else {
+			i, _ = parseSquash(json, i)
+		}
+	case '[':
+		if descend {
+			i = r.array(i+1, acts)
+		} else {
+			i, _ = parseSquash(json, i)
+		}
+	case '"':
+		var ok bool
+		i, _, vesc, ok = parseString(json, i+1)
+		if !ok {
+			return i
+		}
+	case 't', 'f':
+		i, _ = parseLiteral(json, i)
+	case 'n':
+		if i+1 < len(json) && json[i+1] != 'u' {
+			i, _ = parseNumber(json, i)
+		} else {
+			i, _ = parseLiteral(json, i)
+		}
+	default:
+		i, _ = parseNumber(json, i)
+	}
+	for _, a := range acts {
+		for _, slot := range a.n.targets {
+			if !a.f.res[slot].Exists() {
+				a.f.res[slot] = mpResult(json, start, i, vesc)
+			}
+		}
+	}
+	return i
+}
+
+// mpResult returns the Result for the value at json[start:end].
+func mpResult(json string, start, end int, vesc bool) Result {
+	res := Result{Raw: json[start:end], Index: start}
+	switch json[start] {
+	case '{', '[':
+		res.Type = JSON
+	case '"':
+		res.Type = String
+		if vesc {
+			res.Str = unescape(res.Raw[1 : len(res.Raw)-1])
+		} else {
+			res.Str = res.Raw[1 : len(res.Raw)-1]
+		}
+	case 't':
+		res.Type = True
+	case 'f':
+		res.Type = False
+	case 'n':
+		if len(res.Raw) > 1 && res.Raw[1] == 'u' {
+			res.Type = Null
+			break
+		}
+		fallthrough
+	default:
+		res.Type = Number
+		res.Num, _ = strconv.ParseFloat(res.Raw, 64)
+	}
+	return res
+}
+
+// object walks the members of an object. The '{' has already been read.
+func (r *mpRunner) object(i int, acts []mpAct) int {
+	json := r.json
+	for i < len(json) {
+		for ; i < len(json); i++ {
+			if json[i] == '"' {
+				break
+			}
+			if json[i] == '}' {
+				return i + 1
+			}
+		}
+		if i == len(json) {
+			return i
+		}
+		var key string
+		var kesc, ok bool
+		i, key, kesc, ok = parseString(json, i+1)
+		if !ok {
+			return i
+		}
+		key = key[1 : len(key)-1]
+		if kesc {
+			key = unescape(key)
+		}
+		for ; i < len(json); i++ {
+			if json[i] == ':' {
+				i++
+				break
+			}
+		}
+		for ; i < len(json) && json[i] <= ' '; i++ {
+		}
+		if i == len(json) {
+			return i
+		}
+		mark := len(r.stack)
+		for _, a := range acts {
+			if child, ok := a.n.keys[key]; ok {
+				r.stack = append(r.stack, mpAct{child, a.f})
+			}
+			for _, w := range a.n.wild {
+				if matchLimit(key, w.pattern) {
+					r.stack = append(r.stack, mpAct{w.node, a.f})
+				}
+			}
+		}
+		i = r.value(i, r.stack[mark:])
+		r.stack = r.stack[:mark]
+	}
+	return i
+}
+
+// mpProjState collects the results of a projection for one array.
+type mpProjState struct {
+	act     mpAct
+	frame   mpFrame
+	raws    [][]byte
+	indexes [][]int
+}
+
+// array walks the elements of an array. The '[' has already been read.
+func (r *mpRunner) array(i int, acts []mpAct) int {
+	json := r.json
+	var projs []mpProjState
+	for _, a := range acts {
+		if a.n.proj != nil {
+			n := len(a.n.proj.slots)
+			ps := mpProjState{
+				act:     a,
+				frame:   mpFrame{res: make([]Result, n)},
+				raws:    make([][]byte, n),
+				indexes: make([][]int, n),
+			}
+			for k := range ps.raws {
+				ps.raws[k] = append(make([]byte, 0, 64), '[')
+				ps.indexes[k] = make([]int, 0, 64)
+			}
+			projs = append(projs, ps)
+		}
+	}
+	var h int
+	for i < len(json) {
+		for ; i < len(json); i++ {
+			if json[i] > ' ' && json[i] != ',' {
+				break
+			}
+		}
+		if i == len(json) {
+			break
+		}
+		if json[i] == ']' {
+			i++
+			break
+		}
+		mark := len(r.stack)
+		for _, a := range acts {
+			for _, child := range a.n.idx[h] {
+				r.stack = append(r.stack, mpAct{child, a.f})
+			}
+		}
+		for k := range projs {
+			ps := &projs[k]
+			for j := range ps.frame.res {
+				ps.frame.res[j] = Result{}
+			}
+			r.stack = append(r.stack, mpAct{ps.act.n.proj.prog.root, &ps.frame})
+		}
+		i = r.value(i, r.stack[mark:])
+		r.stack = r.stack[:mark]
+		for k := range projs {
+			ps := &projs[k]
+			for j, res := range ps.frame.res {
+				if !res.Exists() {
+					continue
+				}
+				raw := res.Raw
+				if len(raw) == 0 {
+					raw = res.String()
+				}
+				if len(ps.raws[j]) > 1 {
+					ps.raws[j] = append(ps.raws[j], ',')
+				}
+				ps.raws[j] = append(ps.raws[j], raw...)
+				ps.indexes[j] = append(ps.indexes[j], res.Index)
+			}
+		}
+		h++
+	}
+	for _, a := range acts {
+		for _, slot := range a.n.count {
+			if !a.f.res[slot].Exists() {
+				// the count is not in the json, it has no index
+				a.f.res[slot] = Result{
+					Type: Number,
+					Num:  float64(h),
+					Raw:  strconv.Itoa(h),
+				}
+			}
+		}
+	}
+	for _, ps := range projs {
+		for j, slot := range ps.act.n.proj.slots {
+			if !ps.act.f.res[slot].Exists() {
+				ps.act.f.res[slot] = Result{
+					Type:    JSON,
+					Raw:     string(append(ps.raws[j], ']')),
+					Indexes: ps.indexes[j],
+				}
+			}
+		}
+	}
+	return i
+}
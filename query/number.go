@@ -0,0 +1,271 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// maxDecimalDigits limits how many digits a decimal may expand to. It
+// prevents inputs such as 1e999999999 from allocating huge strings.
+const maxDecimalDigits = 10000
+
+// decimal is a json number split into a sign, its significant digits and a
+// base-10 exponent, such that the value is digits × 10^exp. The digits never
+// have leading or trailing zeros, and are empty when the value is zero.
+type decimal struct {
+	neg    bool
+	digits string
+	exp    int
+}
+
+// parseDecimal parses a json number without going through float64.
+func parseDecimal(s string) (d decimal, ok bool) {
+	i := 0
+	if i < len(s) && (s[i] == '-' || s[i] == '+') {
+		d.neg = s[i] == '-'
+		i++
+	}
+	start := i
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == start {
+		return d, false
+	}
+	mant := s[start:i]
+	var frac string
+	if i < len(s) && s[i] == '.' {
+		i++
+		fs := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == fs {
+			return d, false
+		}
+		frac = s[fs:i]
+	}
+	var exp int
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		eneg := false
+		if i < len(s) && (s[i] == '-' || s[i] == '+') {
+			eneg = s[i] == '-'
+			i++
+		}
+		es := i
+		for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			// saturate instead of overflowing, the digit limit is
+			// checked when the decimal is expanded.
+			if exp < 1<<30 {
+				exp = exp*10 + int(s[i]-'0')
+			}
+		}
+		if i == es {
+			return d, false
+		}
+		if eneg {
+			exp = -exp
+		}
+	}
+	if i != len(s) {
+		return d, false
+	}
+	digits := strings.TrimLeft(mant+frac, "0")
+	exp -= len(frac)
+	for len(digits) > 0 && digits[len(digits)-1] == '0' {
+		digits = digits[:len(digits)-1]
+		exp++
+	}
+	if digits == "" {
+		return decimal{}, true
+	}
+	d.digits = digits
+	d.exp = exp
+	return d, true
+}
+
+// isInt returns true when the decimal has no fractional part.
+func (d decimal) isInt() bool {
+	return d.exp >= 0
+}
+
+// String returns the plain decimal form, without an exponent.
+func (d decimal) String() string {
+	if d.digits == "" {
+		return "0"
+	}
+	var b strings.Builder
+	if d.neg {
+		b.WriteByte('-')
+	}
+	if d.exp >= 0 {
+		b.WriteString(d.digits)
+		b.WriteString(strings.Repeat("0", d.exp))
+		return b.String()
+	}
+	p := len(d.digits) + d.exp
+	if p > 0 {
+		b.WriteString(d.digits[:p])
+		b.WriteByte('.')
+		b.WriteString(d.digits[p:])
+	} else {
+		b.WriteString("0.")
+		b.WriteString(strings.Repeat("0", -p))
+		b.WriteString(d.digits)
+	}
+	return b.String()
+}
+
+// size returns the number of digits needed to print the decimal.
+func (d decimal) size() int {
+	if d.exp >= 0 {
+		return len(d.digits) + d.exp
+	}
+	if p := len(d.digits) + d.exp; p <= 0 {
+		return len(d.digits) - p + 1
+	}
+	return len(d.digits) + 1
+}
+
+// cmp compares two decimals and returns -1, 0 or +1.
+func (d decimal) cmp(o decimal) int {
+	switch {
+	case d.digits == "" && o.digits == "":
+		return 0
+	case d.digits == "":
+		if o.neg {
+			return 1
+		}
+		return -1
+	case o.digits == "":
+		if d.neg {
+			return -1
+		}
+		return 1
+	case d.neg != o.neg:
+		if d.neg {
+			return -1
+		}
+		return 1
+	}
+	c := d.cmpAbs(o)
+	if d.neg {
+		return -c
+	}
+	return c
+}
+
+func (d decimal) cmpAbs(o decimal) int {
+	// compare the position of the most significant digit first, then the
+	// digits themselves. Trailing zeros are stripped, so a plain string
+	// comparison orders the digits correctly.
+	da, oa := len(d.digits)+d.exp, len(o.digits)+o.exp
+	switch {
+	case da < oa:
+		return -1
+	case da > oa:
+		return 1
+	case d.digits < o.digits:
+		return -1
+	case d.digits > o.digits:
+		return 1
+	}
+	return 0
+}
+
+// decimal returns the exact decimal held by a Number or a numeric String.
+func (t Result) decimal(want string) (decimal, error) {
+	var raw string
+	switch t.Type {
+	case Number:
+		raw = t.Raw
+		if raw == "" {
+			raw = t.String()
+		}
+	case String:
+		raw = t.Str
+	default:
+		return decimal{}, t.typeError(want)
+	}
+	d, ok := parseDecimal(raw)
+	if !ok {
+		return decimal{}, fmt.Errorf("%w: %q is not a decimal number",
+			ErrType, raw)
+	}
+	if d.size() > maxDecimalDigits {
+		return decimal{}, fmt.Errorf("%w: %q has too many digits",
+			ErrRange, raw)
+	}
+	return d, nil
+}
+
+// BigInt 返回任意精度的整数表示，带有小数部分的数字会返回ErrLossy。
+// 数字字符串也会被解析，例如 "12345678901234567890"。
+func (t Result) BigInt() (*big.Int, error) {
+	d, err := t.decimal("big.Int")
+	if err != nil {
+		return nil, err
+	}
+	if !d.isInt() {
+		return nil, fmt.Errorf("%w: %s is not an integer", ErrLossy, d)
+	}
+	n, _ := new(big.Int).SetString(d.String(), 10)
+	return n, nil
+}
+
+// BigFloat 返回任意精度的浮点数表示，精度会根据有效数字的位数自动选择。
+func (t Result) BigFloat() (*big.Float, error) {
+	d, err := t.decimal("big.Float")
+	if err != nil {
+		return nil, err
+	}
+	prec := uint(len(d.digits))*4 + 64
+	f, _, err := big.ParseFloat(d.String(), 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRange, err)
+	}
+	return f, nil
+}
+
+// Decimal 返回规范化的精确十进制字符串，不会经过float64转换。
+// 去掉多余的前导零和尾随零，并展开指数，例如 "1.50e2" -> "150"，"-0.0" -> "0"。
+func (t Result) Decimal() (string, error) {
+	d, err := t.decimal("decimal")
+	if err != nil {
+		return "", err
+	}
+	return d.String(), nil
+}
+
+// JSONNumber 返回 encoding/json 的 json.Number 表示，保留原始的数字文本。
+func (t Result) JSONNumber() (json.Number, error) {
+	if t.Type == Number {
+		if t.Raw == "" {
+			return json.Number(t.String()), nil
+		}
+		if _, ok := parseDecimal(t.Raw); ok {
+			return json.Number(t.Raw), nil
+		}
+	}
+	if _, err := t.decimal("json.Number"); err != nil {
+		return "", err
+	}
+	return json.Number(t.Str), nil
+}
+
+// compareNumbers compares two json numbers exactly. The ok result is false
+// when either side is not a valid json number.
+func compareNumbers(a, b string) (c int, ok bool) {
+	da, ok := parseDecimal(a)
+	if !ok {
+		return 0, false
+	}
+	db, ok := parseDecimal(b)
+	if !ok {
+		return 0, false
+	}
+	return da.cmp(db), true
+}
@@ -0,0 +1,216 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetPointer 使用RFC 6901定义的JSON Pointer在json中搜索，例如 "/a/b~1c/0"。
+// 空字符串表示整个json，"~0" 和 "~1" 分别表示 "~" 和 "/"。
+// 指针无效或者值不存在时返回的结果不存在。
+func GetPointer(json, ptr string) Result {
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return Result{}
+	}
+	res := jsonPathRoot(json)
+	for _, token := range tokens {
+		if !res.Exists() {
+			break
+		}
+		res = pointerChild(res, token)
+	}
+	return res
+}
+
+// GetPointerBytes 与GetPointer相同，但使用字节切片作为输入。
+func GetPointerBytes(json []byte, ptr string) Result {
+	return GetPointer(string(json), ptr)
+}
+
+// pointerChild returns the member or element of the value that the token
+// refers to.
+func pointerChild(res Result, token string) (child Result) {
+	switch {
+	case res.IsObject():
+		res.ForEach(func(key, value Result) bool {
+			if key.Str == token {
+				child = value
+				return false
+			}
+			return true
+		})
+	case res.IsArray():
+		n, ok := pointerIndex(token)
+		if !ok {
+			return Result{}
+		}
+		res.ForEach(func(_, value Result) bool {
+			if n == 0 {
+				child = value
+				return false
+			}
+			n--
+			return true
+		})
+	}
+	return child
+}
+
+// pointerIndex parses an array index token. Leading zeros are not allowed,
+// and "-" refers to the element after the last one, which never exists.
+func pointerIndex(token string) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+	n, ok := parseUint(token)
+	if !ok || n > maxJSONPathInt {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("%w: pointer %q must start with '/'",
+			ErrInvalidPath, ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, token := range tokens {
+		if strings.IndexByte(token, '~') == -1 {
+			continue
+		}
+		var b strings.Builder
+		for j := 0; j < len(token); j++ {
+			if token[j] != '~' {
+				b.WriteByte(token[j])
+				continue
+			}
+			j++
+			switch {
+			case j < len(token) && token[j] == '0':
+				b.WriteByte('~')
+			case j < len(token) && token[j] == '1':
+				b.WriteByte('/')
+			default:
+				return nil, fmt.Errorf("%w: pointer %q has an invalid escape",
+					ErrInvalidPath, ptr)
+			}
+		}
+		tokens[i] = b.String()
+	}
+	return tokens, nil
+}
+
+// escapePointer escapes a reference token, "~" becomes "~0" and "/"
+// becomes "~1".
+func escapePointer(token string) string {
+	if strings.IndexByte(token, '~') == -1 && strings.IndexByte(token, '/') == -1 {
+		return token
+	}
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
+
+// PointerToPath 将JSON Pointer转换为等价的查询路径。
+// 键中的 "."、"*"、"?" 等特殊字符会被转义，空指针转换为 "@this"。
+//
+//	"/a/b~1c/0" -> "a.b\/c.0"
+//	"/a.b/*"    -> "a\.b.\*"
+func PointerToPath(ptr string) (string, error) {
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return "", err
+	}
+	if len(tokens) == 0 {
+		return "@this", nil
+	}
+	for i, token := range tokens {
+		if token == "" {
+			return "", fmt.Errorf("%w: pointer %q has an empty key that "+
+				"cannot be a path", ErrInvalidPath, ptr)
+		}
+		tokens[i] = escapeComp(token)
+	}
+	return strings.Join(tokens, "."), nil
+}
+
+// PathToPointer 将由键和索引组成的简单查询路径转换为JSON Pointer。
+// 路径中的转义字符会被还原，"~" 和 "/" 会被转义为 "~0" 和 "~1"。
+// 包含通配符、查询、修饰符或管道的路径无法转换，会返回ErrInvalidPath。
+//
+//	"a\.b.c~d.0" -> "/a.b/c~0d/0"
+func PathToPointer(path string) (string, error) {
+	if path == "@this" {
+		return "", nil
+	}
+	invalid := func(why string) (string, error) {
+		return "", fmt.Errorf("%w: path %q %s", ErrInvalidPath, path, why)
+	}
+	if path == "" {
+		return invalid("is empty")
+	}
+	var b strings.Builder
+	var comp []byte
+	start := true
+	flush := func() {
+		b.WriteByte('/')
+		b.WriteString(escapePointer(string(comp)))
+		comp = comp[:0]
+	}
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '\\':
+			i++
+			if i == len(path) {
+				return invalid("ends with an escape")
+			}
+			comp = append(comp, path[i])
+			start = false
+			continue
+		case c == '.':
+			if start {
+				return invalid("has an empty key")
+			}
+			flush()
+			start = true
+			continue
+		case c == '*' || c == '?':
+			return invalid("has a wildcard")
+		case c == '#':
+			return invalid("has an array query")
+		case c == '|':
+			return invalid("has a pipe")
+		case start && (c == '@' || c == '!' || c == '[' || c == '{'):
+			return invalid("has a modifier or selector")
+		}
+		comp = append(comp, c)
+		start = false
+	}
+	if start {
+		return invalid("has an empty key")
+	}
+	flush()
+	return b.String(), nil
+}
+
+// Pointer 返回结果在原始json中的JSON Pointer，与 Path 对应。
+// json参数必须是调用Get时使用的原始json，根节点返回空字符串。
+// 无法确定位置时返回的ok为false。
+func (t Result) Pointer(json string) (ptr string, ok bool) {
+	comps, ok := t.pathComps(json)
+	if !ok {
+		return "", false
+	}
+	var b strings.Builder
+	for _, comp := range comps {
+		b.WriteByte('/')
+		b.WriteString(escapePointer(comp))
+	}
+	return b.String(), true
+}
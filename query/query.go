@@ -29,12 +29,22 @@ func NewQuery() *Query {
 
 // Get 查询指定路径的结果。结果应该是一个JSON数组或对象。
 func (t Result) Get(path string) Result {
-	r := Get(t.Raw, path)
-	if r.Indexes != nil {
-		for i := 0; i < len(r.Indexes); i++ {
+	return t.getPath(defaultEngine, path, nil)
+}
+
+// getPath is Result.Get with an engine and an optional compiled form of the
+// path.
+func (t Result) getPath(e *Engine, path string, p *Path) Result {
+	r := getPath(e, t.Raw, path, p)
+	// a zero index is a value that is not in the json, such as the result
+	// of a modifier or of a nested projection, it stays zero
+	for i := 0; i < len(r.Indexes); i++ {
+		if r.Indexes[i] > 0 {
 			r.Indexes[i] += t.Index
 		}
-	} else {
+	}
+	if r.Index > 0 {
+		// for a projection, the array that a slice selects from
 		r.Index += t.Index
 	}
 	return r
@@ -431,10 +441,12 @@ type arrayPathResult struct {
 		path  string
 		op    string
 		value string
+		expr  *queryExpr // boolean filter expression, see parseQueryExpr
+		err   error      // syntax error of the filter expression
 	}
 }
 
-func parseArrayPath(path string) (r arrayPathResult) {
+func parseArrayPath(e *Engine, path string) (r arrayPathResult) {
 	for i := 0; i < len(path); i++ {
 		if path[i] == '|' {
 			r.part = path[:i]
@@ -444,7 +456,7 @@ func parseArrayPath(path string) (r arrayPathResult) {
 		}
 		if path[i] == '.' {
 			r.part = path[:i]
-			if !r.arrch && i < len(path)-1 && isDotPiperChar(path[i+1]) {
+			if !r.arrch && i < len(path)-1 && isDotPiperChar(e, path[i+1:]) {
 				r.pipe = path[i+1:]
 				r.piped = true
 			} else {
@@ -479,6 +491,11 @@ func parseArrayPath(path string) (r arrayPathResult) {
 					r.query.path = qpath
 					r.query.op = op
 					r.query.value = value
+					if expr, complex, err := parseQueryExpr(
+						path[2:fi-1], op); complex {
+						r.query.path, r.query.op, r.query.value = "", "", ""
+						r.query.expr, r.query.err = expr, err
+					}
 
 					i = fi - 1
 					if i+1 < len(path) && path[i+1] == '#' {
@@ -525,6 +542,12 @@ func parseQuery(query string) (
 				// start of the value part
 				j = i
 				continue
+			case '~':
+				// only a regex operator, otherwise part of the key
+				if isRegexOp(query[i:]) {
+					j = i
+					continue
+				}
 			}
 		}
 		if query[i] == '\\' {
@@ -561,6 +584,8 @@ func parseQuery(query string) (
 		switch {
 		case len(value) == 1:
 			opsz = 1
+		case isRegexOp(value):
+			opsz = strings.IndexByte(value, '=') + 1
 		case value[0] == '!' && value[1] == '=':
 			opsz = 2
 		case value[0] == '!' && value[1] == '%':
@@ -604,9 +629,12 @@ right:
 	return s
 }
 
-// peek at the next byte and see if it's a '@', '[', or '{'.
-func isDotPiperChar(c byte) bool {
-	return !DisableModifiers && (c == '@' || c == '[' || c == '{')
+// peek at the next byte and see if it's a '@', '[', or '{'. A '[' that
+// starts a slice such as '[1:4]' is not a selector.
+func isDotPiperChar(e *Engine, path string) bool {
+	c := path[0]
+	return !e.modifiersDisabled() && (c == '@' || c == '{' ||
+		(c == '[' && !isSliceComp(path)))
 }
 
 type objectPathResult struct {
@@ -618,7 +646,7 @@ type objectPathResult struct {
 	more  bool
 }
 
-func parseObjectPath(path string) (r objectPathResult) {
+func parseObjectPath(e *Engine, path string) (r objectPathResult) {
 	for i := 0; i < len(path); i++ {
 		if path[i] == '|' {
 			r.part = path[:i]
@@ -628,7 +656,7 @@ func parseObjectPath(path string) (r objectPathResult) {
 		}
 		if path[i] == '.' {
 			r.part = path[:i]
-			if i < len(path)-1 && isDotPiperChar(path[i+1]) {
+			if i < len(path)-1 && isDotPiperChar(e, path[i+1:]) {
 				r.pipe = path[i+1:]
 				r.piped = true
 			} else {
@@ -658,7 +686,7 @@ func parseObjectPath(path string) (r objectPathResult) {
 						continue
 					} else if path[i] == '.' {
 						r.part = string(epart)
-						if i < len(path)-1 && isDotPiperChar(path[i+1]) {
+						if i < len(path)-1 && isDotPiperChar(e, path[i+1:]) {
 							r.pipe = path[i+1:]
 							r.piped = true
 						} else {
@@ -734,12 +762,20 @@ func parseSquash(json string, i int) (int, string) {
 	return i, json[s:]
 }
 
-func parseObject(c *parseContext, i int, path string) (int, bool) {
+func parseObject(c *parseContext, i int, path string, pc *pathComp) (int, bool) {
 	var pmatch, kesc, vesc, ok, hit bool
 	var key, val string
-	rp := parseObjectPath(path)
+	var rp objectPathResult
+	var next *pathComp
+	var pipe *Path
+	if pc != nil {
+		rp, next, pipe = pc.obj, pc.objNext, pc.objPipe
+	} else {
+		rp = parseObjectPath(c.e, path)
+	}
 	if !rp.more && rp.piped {
 		c.pipe = rp.pipe
+		c.pipePath = pipe
 		c.piped = true
 	}
 	for i < len(c.json) {
@@ -833,7 +869,7 @@ func parseObject(c *parseContext, i int, path string) (int, bool) {
 				}
 			case '{':
 				if pmatch && !hit {
-					i, hit = parseObject(c, i+1, rp.path)
+					i, hit = parseObject(c, i+1, rp.path, next)
 					if hit {
 						return i, true
 					}
@@ -847,7 +883,7 @@ func parseObject(c *parseContext, i int, path string) (int, bool) {
 				}
 			case '[':
 				if pmatch && !hit {
-					i, hit = parseArray(c, i+1, rp.path)
+					i, hit = parseArray(c, i+1, rp.path, next)
 					if hit {
 						return i, true
 					}
@@ -905,9 +941,13 @@ func matchLimit(str, pattern string) bool {
 	return matched
 }
 
-func queryMatches(rp *arrayPathResult, value Result) bool {
-	rpv := rp.query.value
-	if len(rpv) > 0 && rpv[0] == '~' {
+func queryMatches(e *Engine, rp *arrayPathResult, value Result) bool {
+	return matchesQuery(e, rp.query.op, rp.query.value, value)
+}
+
+// matchesQuery compares the value to the query value rpv with the op.
+func matchesQuery(e *Engine, op, rpv string, value Result) bool {
+	if len(rpv) > 0 && rpv[0] == '~' && !isRegexOp(op) {
 		// convert to bool
 		rpv = rpv[1:]
 		if value.Bool() {
@@ -919,7 +959,7 @@ func queryMatches(rp *arrayPathResult, value Result) bool {
 	if !value.Exists() {
 		return false
 	}
-	if rp.query.op == "" {
+	if op == "" {
 		// the query is only looking for existence, such as:
 		//   friends.#(name)
 		// which makes sure that the array "friends" has an element of
@@ -928,7 +968,7 @@ func queryMatches(rp *arrayPathResult, value Result) bool {
 	}
 	switch value.Type {
 	case String:
-		switch rp.query.op {
+		switch op {
 		case "=":
 			return value.Str == rpv
 		case "!=":
@@ -945,10 +985,35 @@ func queryMatches(rp *arrayPathResult, value Result) bool {
 			return matchLimit(value.Str, rpv)
 		case "!%":
 			return !matchLimit(value.Str, rpv)
+		case "~=", "~*=", "!~=", "!~*=":
+			return matchRegex(e, op, value.Str, rpv)
 		}
 	case Number:
+		if e.preciseNumbers() {
+			raw := value.Raw
+			if raw == "" {
+				raw = value.String()
+			}
+			if c, ok := compareNumbers(raw, rpv); ok {
+				switch op {
+				case "=":
+					return c == 0
+				case "!=":
+					return c != 0
+				case "<":
+					return c < 0
+				case "<=":
+					return c <= 0
+				case ">":
+					return c > 0
+				case ">=":
+					return c >= 0
+				}
+				return false
+			}
+		}
 		rpvn, _ := strconv.ParseFloat(rpv, 64)
-		switch rp.query.op {
+		switch op {
 		case "=":
 			return value.Num == rpvn
 		case "!=":
@@ -963,7 +1028,7 @@ func queryMatches(rp *arrayPathResult, value Result) bool {
 			return value.Num >= rpvn
 		}
 	case True:
-		switch rp.query.op {
+		switch op {
 		case "=":
 			return rpv == "true"
 		case "!=":
@@ -974,7 +1039,7 @@ func queryMatches(rp *arrayPathResult, value Result) bool {
 			return true
 		}
 	case False:
-		switch rp.query.op {
+		switch op {
 		case "=":
 			return rpv == "false"
 		case "!=":
@@ -987,7 +1052,7 @@ func queryMatches(rp *arrayPathResult, value Result) bool {
 	}
 	return false
 }
-func parseArray(c *parseContext, i int, path string) (int, bool) {
+func parseArray(c *parseContext, i int, path string, pc *pathComp) (int, bool) {
 	var pmatch, vesc, ok, hit bool
 	var val string
 	var h int
@@ -995,7 +1060,13 @@ func parseArray(c *parseContext, i int, path string) (int, bool) {
 	var partidx int
 	var multires []byte
 	var queryIndexes []int
-	rp := parseArrayPath(path)
+	var rp arrayPathResult
+	var cp arrayPathComp
+	if pc != nil {
+		rp, cp = pc.arr, pc.arrComp
+	} else {
+		rp = parseArrayPath(c.e, path)
+	}
 	if !rp.arrch {
 		n, ok := parseUint(rp.part)
 		if !ok {
@@ -1006,8 +1077,14 @@ func parseArray(c *parseContext, i int, path string) (int, bool) {
 	}
 	if !rp.more && rp.piped {
 		c.pipe = rp.pipe
+		c.pipePath = cp.pipe
 		c.piped = true
 	}
+	if partidx == -1 {
+		if s, ok := parseSlice(rp.part); ok {
+			return parseArraySlice(c, i, &rp, s)
+		}
+	}
 
 	procQuery := func(qval Result) bool {
 		if rp.query.all {
@@ -1020,23 +1097,30 @@ func parseArray(c *parseContext, i int, path string) (int, bool) {
 		fillIndex(c.json, &tmp)
 		parentIndex := tmp.value.Index
 		var res Result
-		if qval.Type == JSON {
-			res = qval.Get(rp.query.path)
+		var matched bool
+		if rp.query.expr != nil || rp.query.err != nil {
+			matched = rp.query.err == nil && rp.query.expr.match(c.e, qval)
 		} else {
-			if rp.query.path != "" {
-				return false
+			if qval.Type == JSON {
+				res = qval.getPath(c.e, rp.query.path, cp.query)
+			} else {
+				if rp.query.path != "" {
+					return false
+				}
+				res = qval
 			}
-			res = qval
+			matched = queryMatches(c.e, &rp, res)
 		}
-		if queryMatches(&rp, res) {
+		if matched {
 			if rp.more {
 				left, right, ok := splitPossiblePipe(rp.path)
 				if ok {
 					rp.path = left
 					c.pipe = right
+					c.pipePath = cp.morePipe
 					c.piped = true
 				}
-				res = qval.Get(rp.path)
+				res = qval.getPath(c.e, rp.path, cp.more)
 			} else {
 				res = qval
 			}
@@ -1114,7 +1198,7 @@ func parseArray(c *parseContext, i int, path string) (int, bool) {
 				}
 			case '{':
 				if pmatch && !hit {
-					i, hit = parseObject(c, i+1, rp.path)
+					i, hit = parseObject(c, i+1, rp.path, cp.next)
 					if hit {
 						if rp.alogok {
 							break
@@ -1138,7 +1222,7 @@ func parseArray(c *parseContext, i int, path string) (int, bool) {
 				}
 			case '[':
 				if pmatch && !hit {
-					i, hit = parseArray(c, i+1, rp.path)
+					i, hit = parseArray(c, i+1, rp.path, cp.next)
 					if hit {
 						if rp.alogok {
 							break
@@ -1204,6 +1288,7 @@ func parseArray(c *parseContext, i int, path string) (int, bool) {
 						if ok {
 							rp.alogkey = left
 							c.pipe = right
+							c.pipePath = cp.alogPipe
 							c.piped = true
 						}
 						var indexes = make([]int, 0, 64)
@@ -1222,7 +1307,7 @@ func parseArray(c *parseContext, i int, path string) (int, bool) {
 							if idx < len(c.json) && c.json[idx] != ']' {
 								_, res, ok := parseAny(c.json, idx, true)
 								if ok {
-									res := res.Get(rp.alogkey)
+									res := res.getPath(c.e, rp.alogkey, cp.alog)
 									if res.Exists() {
 										if k > 0 {
 											jsons = append(jsons, ',')
@@ -1510,27 +1595,46 @@ type parseContext struct {
 	piped bool
 	calcd bool
 	lines bool
+
+	// pipePath is the compiled form of pipe, if any
+	pipePath *Path
+	e        *Engine
 }
 
 // Get 在json中搜索指定路径。
 // 路径使用.分割，比如："name.last" 或 "age"
 // 当找到值时，它会立即返回。
+// 包级别的函数使用默认引擎，默认引擎没有任何限制且不能设置，
+// 查询不可信的json时请使用 NewEngine 创建设置了限制的引擎。
 func Get(json, path string) Result {
+	return getPath(defaultEngine, json, path, nil)
+}
+
+// getPath evaluates the path against json with the engine. When p is not
+// nil it must be the compiled form of path, and its pre-parsed components are
+// used instead of parsing the path again.
+func getPath(e *Engine, json, path string, p *Path) Result {
 	if len(path) > 1 {
-		if (path[0] == '@' && !DisableModifiers) || path[0] == '!' {
+		if d := deepOf(path, p); d != nil {
+			return getDeep(e, json, d)
+		}
+		if (path[0] == '@' && !e.modifiersDisabled()) ||
+			(path[0] == '!' && !e.staticDisabled()) {
 			// possible modifier
 			var ok bool
 			var npath string
 			var rjson string
-			if path[0] == '@' && !DisableModifiers {
-				npath, rjson, ok = execModifier(json, path)
+			if p != nil {
+				npath, rjson, ok = p.execHead(json)
+			} else if path[0] == '@' {
+				npath, rjson, ok = e.execModifier(json, path)
 			} else if path[0] == '!' {
 				npath, rjson, ok = execStatic(json, path)
 			}
 			if ok {
 				path = npath
 				if len(path) > 0 && (path[0] == '|' || path[0] == '.') {
-					res := Get(rjson, path[1:])
+					res := getPath(e, rjson, path[1:], p.nextPath())
 					res.Index = 0
 					res.Indexes = nil
 					return res
@@ -1540,19 +1644,29 @@ func Get(json, path string) Result {
 		}
 
 		// 查询数组
-		if path[0] == '[' || path[0] == '{' {
+		if (path[0] == '[' && !isSliceComp(path)) || path[0] == '{' {
 			// using a subselector path
 			kind := path[0]
 			var ok bool
 			var subs []subSelector
-			subs, path, ok = parseSubSelectors(path)
+			var subPaths []*Path
+			if p != nil && p.subs != nil {
+				subs, path, ok = p.subs, p.rest, true
+				subPaths = p.subPaths
+			} else {
+				subs, path, ok = parseSubSelectors(path)
+			}
 			if ok {
 				if len(path) == 0 || (path[0] == '|' || path[0] == '.') {
 					var b []byte
 					b = append(b, kind)
 					var i int
-					for _, sub := range subs {
-						res := Get(json, sub.path)
+					for j, sub := range subs {
+						var subPath *Path
+						if subPaths != nil {
+							subPath = subPaths[j]
+						}
+						res := getPath(e, json, sub.path, subPath)
 						if res.Exists() {
 							if i > 0 {
 								b = append(b, ',')
@@ -1592,7 +1706,7 @@ func Get(json, path string) Result {
 					res.Raw = string(b)
 					res.Type = JSON
 					if len(path) > 0 {
-						res = res.Get(path[1:])
+						res = res.getPath(e, path[1:], p.nextPath())
 					}
 					res.Index = 0
 					return res
@@ -1601,26 +1715,30 @@ func Get(json, path string) Result {
 		}
 	}
 	var i int
-	var c = &parseContext{json: json}
+	var c = &parseContext{json: json, e: e}
+	var pc *pathComp
+	if p != nil {
+		pc = p.comp
+	}
 	if len(path) >= 2 && path[0] == '.' && path[1] == '.' {
 		c.lines = true
-		parseArray(c, 0, path[2:])
+		parseArray(c, 0, path[2:], pc)
 	} else {
 		for ; i < len(c.json); i++ {
 			if c.json[i] == '{' {
 				i++
-				parseObject(c, i, path)
+				parseObject(c, i, path, pc)
 				break
 			}
 			if c.json[i] == '[' {
 				i++
-				parseArray(c, i, path)
+				parseArray(c, i, path, pc)
 				break
 			}
 		}
 	}
 	if c.piped {
-		res := c.value.Get(c.pipe)
+		res := c.value.getPath(e, c.pipe, c.pipePath)
 		res.Index = 0
 		return res
 	}
@@ -1631,7 +1749,7 @@ func Get(json, path string) Result {
 // GetBytes 在json中搜索指定路径。
 // 如果使用字节，此方法优于Get(string(data)， path)
 func GetBytes(json []byte, path string) Result {
-	return getBytes(json, path)
+	return getBytes(defaultEngine, json, path, nil)
 }
 
 // runeit returns the rune from the the \uXXXX
@@ -1838,22 +1956,15 @@ func parseAny(json string, i int, hit bool) (int, Result, bool) {
 
 // GetMany 在json中搜索多个路径。
 // 返回值是一个Result数组，其中项的数量将等于输入路径的数量。
+// 简单的路径会在一次遍历中同时完成查询，需要重复查询同一组路径时请使用 CompileMany。
 func GetMany(json string, path ...string) []Result {
-	res := make([]Result, len(path))
-	for i, path := range path {
-		res[i] = Get(json, path)
-	}
-	return res
+	return newMultiPath(defaultEngine, path).Get(json)
 }
 
 // GetManyBytes 在json中搜索多个路径
 // 返回值是一个Result数组，其中项的数量将等于输入路径的数量。
 func GetManyBytes(json []byte, path ...string) []Result {
-	res := make([]Result, len(path))
-	for i, path := range path {
-		res[i] = GetBytes(json, path)
-	}
-	return res
+	return newMultiPath(defaultEngine, path).GetBytes(json)
 }
 
 func validpayload(data []byte, i int) (outi int, ok bool) {
@@ -2218,10 +2329,20 @@ func execStatic(json, path string) (pathOut, res string, ok bool) {
 	return pathOut, res, false
 }
 
-// execModifier parses the path to find a matching modifier function.
-// The input expects that the path already starts with a '@'
-func execModifier(json, path string) (pathOut, res string, ok bool) {
-	name := path[1:]
+// execModifier parses the path to find a matching modifier function of the
+// engine. The input expects that the path already starts with a '@'
+func (e *Engine) execModifier(json, path string) (pathOut, res string, ok bool) {
+	name, args, pathOut := parseModifier(path)
+	if fn, ok := e.modifier(name); ok {
+		return pathOut, fn(json, args), true
+	}
+	return pathOut, res, false
+}
+
+// parseModifier splits a path that starts with a '@' into the modifier name,
+// its arguments and the remaining path.
+func parseModifier(path string) (name, args, pathOut string) {
+	name = path[1:]
 	var hasArgs bool
 	for i := 1; i < len(path); i++ {
 		if path[i] == ':' {
@@ -2241,33 +2362,29 @@ func execModifier(json, path string) (pathOut, res string, ok bool) {
 			break
 		}
 	}
-	if fn, ok := modifiers[name]; ok {
-		var args string
-		if hasArgs {
-			var parsedArgs bool
-			switch pathOut[0] {
-			case '{', '[', '"':
-				res := Parse(pathOut)
-				if res.Exists() {
-					args = squash(pathOut)
-					pathOut = pathOut[len(args):]
-					parsedArgs = true
-				}
+	if hasArgs {
+		var parsedArgs bool
+		switch pathOut[0] {
+		case '{', '[', '"':
+			res := Parse(pathOut)
+			if res.Exists() {
+				args = squash(pathOut)
+				pathOut = pathOut[len(args):]
+				parsedArgs = true
 			}
-			if !parsedArgs {
-				idx := strings.IndexByte(pathOut, '|')
-				if idx == -1 {
-					args = pathOut
-					pathOut = ""
-				} else {
-					args = pathOut[:idx]
-					pathOut = pathOut[idx:]
-				}
+		}
+		if !parsedArgs {
+			idx := strings.IndexByte(pathOut, '|')
+			if idx == -1 {
+				args = pathOut
+				pathOut = ""
+			} else {
+				args = pathOut[:idx]
+				pathOut = pathOut[idx:]
 			}
 		}
-		return pathOut, fn(json, args), true
 	}
-	return pathOut, res, false
+	return name, args, pathOut
 }
 
 // unwrap removes the '[]' or '{}' characters around json
@@ -2282,28 +2399,42 @@ func unwrap(json string) string {
 // DisableModifiers will disable the modifier syntax
 var DisableModifiers = false
 
+// modifiers are the built-in modifiers that every engine starts with.
 var modifiers = map[string]func(json, arg string) string{
-	"pretty":  modPretty,
-	"ugly":    modUgly,
-	"reverse": modReverse,
-	"this":    modThis,
-	"flatten": modFlatten,
-	"join":    modJoin,
-	"valid":   modValid,
-	"keys":    modKeys,
-	"values":  modValues,
-}
-
-// AddModifier 将一个自定义修饰符命令绑定到GJSON语法。
-// 这个操作不是线程安全的，应该在使用所有其他查询函数之前执行。
+	"pretty":   modPretty,
+	"ugly":     modUgly,
+	"reverse":  modReverse,
+	"this":     modThis,
+	"flatten":  modFlatten,
+	"join":     modJoin,
+	"valid":    modValid,
+	"keys":     modKeys,
+	"values":   modValues,
+	"sum":      modSum,
+	"avg":      modAvg,
+	"min":      modMin,
+	"max":      modMax,
+	"count":    modCount,
+	"distinct": modDistinct,
+	"sort":     modSort,
+	"groupby":  modGroupBy,
+	"pick":     modPick,
+	"omit":     modOmit,
+	"rename":   modRename,
+	"tostr":    modToStr,
+	"fromstr":  modFromStr,
+	"default":  modDefault,
+}
+
+// AddModifier 将一个自定义修饰符命令绑定到默认引擎的查询语法。
+// 这个操作是线程安全的，可以在查询的同时执行。
 func AddModifier(name string, fn func(json, arg string) string) {
-	modifiers[name] = fn
+	defaultEngine.AddModifier(name, fn)
 }
 
-// ModifierExists 当指定的修饰符存在时返回true。
+// ModifierExists 当默认引擎中存在指定的修饰符时返回true。
 func ModifierExists(name string, fn func(json, arg string) string) bool {
-	_, ok := modifiers[name]
-	return ok
+	return defaultEngine.ModifierExists(name)
 }
 
 // cleanWS remove any non-whitespace from string
@@ -2576,6 +2707,148 @@ func modValid(json, arg string) string {
 	return json
 }
 
+// @pick keeps the listed keys of an object, in the order of the object.
+// Arrays of objects are picked element by element.
+//   {"first":"Tom","last":"Smith","age":37} -> @pick:["first","age"]
+//   -> {"first":"Tom","age":37}
+func modPick(json, arg string) string {
+	keys := modKeyList(arg)
+	return mapObjects(json, func(key string) (string, bool) {
+		return key, keys[key]
+	})
+}
+
+// @omit drops the listed keys of an object, or of each object of an array.
+//   {"first":"Tom","last":"Smith","age":37} -> @omit:["age"]
+//   -> {"first":"Tom","last":"Smith"}
+func modOmit(json, arg string) string {
+	keys := modKeyList(arg)
+	return mapObjects(json, func(key string) (string, bool) {
+		return key, !keys[key]
+	})
+}
+
+// @rename renames the keys of an object, or of each object of an array.
+//   {"first":"Tom","age":37} -> @rename:{"first":"name"}
+//   -> {"name":"Tom","age":37}
+func modRename(json, arg string) string {
+	names := make(map[string]string)
+	Parse(arg).ForEach(func(key, value Result) bool {
+		names[key.String()] = value.String()
+		return true
+	})
+	return mapObjects(json, func(key string) (string, bool) {
+		if name, ok := names[key]; ok {
+			return name, true
+		}
+		return key, true
+	})
+}
+
+// modKeyList parses the keys of @pick and @omit, which are either an array
+// of keys or a single key.
+func modKeyList(arg string) map[string]bool {
+	keys := make(map[string]bool)
+	res := Parse(arg)
+	if res.IsArray() {
+		res.ForEach(func(_, value Result) bool {
+			keys[value.String()] = true
+			return true
+		})
+	} else if arg != "" {
+		keys[res.String()] = true
+	}
+	return keys
+}
+
+// mapObjects rebuilds an object, or each object of an array, with the keys
+// returned by fn. Members are dropped when fn returns false. Other json is
+// returned unchanged.
+func mapObjects(json string, fn func(key string) (string, bool)) string {
+	res := Parse(json)
+	switch {
+	case res.IsObject():
+		return bytesString(appendMappedObject(nil, res, fn))
+	case res.IsArray():
+		out := []byte{'['}
+		var i int
+		res.ForEach(func(_, value Result) bool {
+			if i > 0 {
+				out = append(out, ',')
+			}
+			if value.IsObject() {
+				out = appendMappedObject(out, value, fn)
+			} else {
+				out = append(out, value.Raw...)
+			}
+			i++
+			return true
+		})
+		return bytesString(append(out, ']'))
+	}
+	return json
+}
+
+func appendMappedObject(out []byte, obj Result,
+	fn func(key string) (string, bool)) []byte {
+	out = append(out, '{')
+	var i int
+	obj.ForEach(func(key, value Result) bool {
+		name, ok := fn(key.String())
+		if !ok {
+			return true
+		}
+		if i > 0 {
+			out = append(out, ',')
+		}
+		if name == key.String() {
+			out = append(out, key.Raw...)
+		} else {
+			out = appendJSONString(out, name)
+		}
+		out = append(out, ':')
+		out = append(out, value.Raw...)
+		i++
+		return true
+	})
+	return append(out, '}')
+}
+
+// @tostr converts json to a string that contains the json.
+//   {"id":1} -> "{\"id\":1}"
+func modToStr(json, arg string) string {
+	json = strings.TrimSpace(json)
+	if json == "" {
+		return ""
+	}
+	return bytesString(appendJSONString(nil, json))
+}
+
+// @fromstr parses the json that is embedded in a string. An empty string is
+// returned when the string does not contain valid json.
+//   "{\"id\":1}" -> {"id":1}
+func modFromStr(json, arg string) string {
+	res := Parse(json)
+	if res.Type != String || !Valid(res.Str) {
+		return ""
+	}
+	return res.Str
+}
+
+// @default returns the arg when the path is missing, otherwise the json is
+// returned unchanged. An arg that is not valid json is used as a string.
+//   missing|@default:0 -> 0
+//   missing|@default:n/a -> "n/a"
+func modDefault(json, arg string) string {
+	if strings.TrimSpace(json) != "" {
+		return json
+	}
+	if Valid(arg) {
+		return arg
+	}
+	return bytesString(appendJSONString(nil, arg))
+}
+
 // stringHeader instead of reflect.StringHeader
 type stringHeader struct {
 	data unsafe.Pointer
@@ -2592,46 +2865,53 @@ type sliceHeader struct {
 // getBytes casts the input json bytes to a string and safely returns the
 // results as uniquely allocated data. This operation is intended to minimize
 // copies and allocations for the large json string->[]byte.
-func getBytes(json []byte, path string) Result {
+func getBytes(e *Engine, json []byte, path string, p *Path) Result {
 	var result Result
 	if json != nil {
 		// unsafe cast to string
-		result = Get(*(*string)(unsafe.Pointer(&json)), path)
-		// safely get the string headers
-		rawhi := *(*stringHeader)(unsafe.Pointer(&result.Raw))
-		strhi := *(*stringHeader)(unsafe.Pointer(&result.Str))
-		// create byte slice headers
-		rawh := sliceHeader{data: rawhi.data, len: rawhi.len, cap: rawhi.len}
-		strh := sliceHeader{data: strhi.data, len: strhi.len, cap: rawhi.len}
-		if strh.data == nil {
-			// str is nil
-			if rawh.data == nil {
-				// raw is nil
-				result.Raw = ""
-			} else {
-				// raw has data, safely copy the slice header to a string
-				result.Raw = string(*(*[]byte)(unsafe.Pointer(&rawh)))
-			}
-			result.Str = ""
-		} else if rawh.data == nil {
+		result = getPath(e, *(*string)(unsafe.Pointer(&json)), path, p)
+		result = copyResult(result)
+	}
+	return result
+}
+
+// copyResult safely copies the strings of a result that was taken from an
+// unsafe cast of a byte slice, so that it no longer references the slice.
+func copyResult(result Result) Result {
+	// safely get the string headers
+	rawhi := *(*stringHeader)(unsafe.Pointer(&result.Raw))
+	strhi := *(*stringHeader)(unsafe.Pointer(&result.Str))
+	// create byte slice headers
+	rawh := sliceHeader{data: rawhi.data, len: rawhi.len, cap: rawhi.len}
+	strh := sliceHeader{data: strhi.data, len: strhi.len, cap: rawhi.len}
+	if strh.data == nil {
+		// str is nil
+		if rawh.data == nil {
 			// raw is nil
 			result.Raw = ""
-			// str has data, safely copy the slice header to a string
-			result.Str = string(*(*[]byte)(unsafe.Pointer(&strh)))
-		} else if uintptr(strh.data) >= uintptr(rawh.data) &&
-			uintptr(strh.data)+uintptr(strh.len) <=
-				uintptr(rawh.data)+uintptr(rawh.len) {
-			// Str is a substring of Raw.
-			start := uintptr(strh.data) - uintptr(rawh.data)
-			// safely copy the raw slice header
-			result.Raw = string(*(*[]byte)(unsafe.Pointer(&rawh)))
-			// substring the raw
-			result.Str = result.Raw[start : start+uintptr(strh.len)]
 		} else {
-			// safely copy both the raw and str slice headers to strings
+			// raw has data, safely copy the slice header to a string
 			result.Raw = string(*(*[]byte)(unsafe.Pointer(&rawh)))
-			result.Str = string(*(*[]byte)(unsafe.Pointer(&strh)))
 		}
+		result.Str = ""
+	} else if rawh.data == nil {
+		// raw is nil
+		result.Raw = ""
+		// str has data, safely copy the slice header to a string
+		result.Str = string(*(*[]byte)(unsafe.Pointer(&strh)))
+	} else if uintptr(strh.data) >= uintptr(rawh.data) &&
+		uintptr(strh.data)+uintptr(strh.len) <=
+			uintptr(rawh.data)+uintptr(rawh.len) {
+		// Str is a substring of Raw.
+		start := uintptr(strh.data) - uintptr(rawh.data)
+		// safely copy the raw slice header
+		result.Raw = string(*(*[]byte)(unsafe.Pointer(&rawh)))
+		// substring the raw
+		result.Str = result.Raw[start : start+uintptr(strh.len)]
+	} else {
+		// safely copy both the raw and str slice headers to strings
+		result.Raw = string(*(*[]byte)(unsafe.Pointer(&rawh)))
+		result.Str = string(*(*[]byte)(unsafe.Pointer(&strh)))
 	}
 	return result
 }
@@ -2711,12 +2991,17 @@ func revSquash(json string) string {
 }
 
 func (t Result) Paths(json string) []string {
+	return t.paths(defaultEngine, json)
+}
+
+// paths is Result.Paths with the options of an engine.
+func (t Result) paths(e *Engine, json string) []string {
 	if t.Indexes == nil {
 		return nil
 	}
 	paths := make([]string, 0, len(t.Indexes))
 	t.ForEach(func(_, value Result) bool {
-		paths = append(paths, value.Path(json))
+		paths = append(paths, value.path(e, json))
 		return true
 	})
 	if len(paths) != len(t.Indexes) {
@@ -2728,8 +3013,37 @@ func (t Result) Paths(json string) []string {
 // Path returns the original GJSON path for Result.
 // The json param must be the original JSON used when calling Get.
 func (t Result) Path(json string) string {
+	return t.path(defaultEngine, json)
+}
+
+// path is Result.Path with the options of an engine, the root is "@this"
+// only when the engine allows modifiers.
+func (t Result) path(e *Engine, json string) string {
+	comps, ok := t.pathComps(json)
+	if !ok {
+		return ""
+	}
+	if len(comps) == 0 {
+		if e.modifiersDisabled() {
+			return ""
+		}
+		return "@this"
+	}
 	var path []byte
+	for i, comp := range comps {
+		if i > 0 {
+			path = append(path, '.')
+		}
+		path = append(path, escapeComp(comp)...)
+	}
+	return string(path)
+}
+
+// pathComps returns the keys and indexes that lead from the root of the
+// json to the Result, with the keys unescaped.
+func (t Result) pathComps(json string) ([]string, bool) {
 	var comps []string // raw components
+	var keys []string
 	i := t.Index - 1
 	if t.Index+len(t.Raw) > len(json) {
 		// JSON cannot safely contain Result.
@@ -2786,27 +3100,17 @@ func (t Result) Path(json string) string {
 			}
 		}
 	}
-	if len(comps) == 0 {
-		if DisableModifiers {
-			goto fail
-		}
-		return "@this"
-	}
+	keys = make([]string, 0, len(comps))
 	for i := len(comps) - 1; i >= 0; i-- {
 		rcomp := Parse(comps[i])
 		if !rcomp.Exists() {
 			goto fail
 		}
-		comp := escapeComp(rcomp.String())
-		path = append(path, '.')
-		path = append(path, comp...)
+		keys = append(keys, rcomp.String())
 	}
-	if len(path) > 0 {
-		path = path[1:]
-	}
-	return string(path)
+	return keys, true
 fail:
-	return ""
+	return nil, false
 }
 
 // isSafePathKeyChar returns true if the input character is safe for not
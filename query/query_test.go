@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -2582,3 +2584,929 @@ func TestArrayKeys(t *testing.T) {
 	})
 	assert(t, i == N)
 }
+
+// 测试严格的类型转换
+func TestStrictAccessors(t *testing.T) {
+	jsonStr := `{"zero":0,"big":9007199254740993,"huge":1e400,"frac":1.5,
+		"exp":1e3,"neg":-12,"over":18446744073709551616,"str":"42",
+		"word":"abc","yes":true,"null":null,"obj":{},
+		"time":"2022-07-14T10:00:00Z","bad":"2022/07/14"}`
+
+	n, err := Get(jsonStr, "zero").IntE()
+	assert(t, err == nil && n == 0)
+	n, err = Get(jsonStr, "big").IntE()
+	assert(t, err == nil && n == 9007199254740993)
+	n, err = Get(jsonStr, "exp").IntE()
+	assert(t, err == nil && n == 1000)
+	n, err = Get(jsonStr, "str").IntE()
+	assert(t, err == nil && n == 42)
+	_, err = Get(jsonStr, "frac").IntE()
+	assert(t, errors.Is(err, ErrLossy))
+	_, err = Get(jsonStr, "over").IntE()
+	assert(t, errors.Is(err, ErrRange))
+	_, err = Get(jsonStr, "word").IntE()
+	assert(t, errors.Is(err, ErrType))
+	_, err = Get(jsonStr, "yes").IntE()
+	assert(t, errors.Is(err, ErrType))
+	_, err = Get(jsonStr, "missing").IntE()
+	assert(t, errors.Is(err, ErrNotExist))
+	_, err = Get(jsonStr, "null").IntE()
+	assert(t, errors.Is(err, ErrType))
+	n, err = Get(`[1,2]`, "#").IntE()
+	assert(t, err == nil && n == 2)
+	// float64 would round these to integers
+	n, err = Parse("9007199254740993.0").IntE()
+	assert(t, err == nil && n == 9007199254740993)
+	_, err = Parse("9007199254740993.5").IntE()
+	assert(t, errors.Is(err, ErrLossy))
+	_, err = Parse("1.0000000000000001").IntE()
+	assert(t, errors.Is(err, ErrLossy))
+	_, err = Parse(`"1.0000000000000001"`).IntE()
+	assert(t, errors.Is(err, ErrLossy))
+	_, err = Parse("9223372036854775808").IntE()
+	assert(t, errors.Is(err, ErrRange))
+	n, err = Parse("-9223372036854775808").IntE()
+	assert(t, err == nil && n == math.MinInt64)
+	_, err = Parse("1e400").IntE()
+	assert(t, errors.Is(err, ErrRange))
+
+	u, err := Get(jsonStr, "big").UintE()
+	assert(t, err == nil && u == 9007199254740993)
+	_, err = Get(jsonStr, "neg").UintE()
+	assert(t, errors.Is(err, ErrRange))
+	_, err = Get(jsonStr, "over").UintE()
+	assert(t, errors.Is(err, ErrRange))
+	_, err = Get(jsonStr, "frac").UintE()
+	assert(t, errors.Is(err, ErrLossy))
+	_, err = Parse("1.0000000000000001").UintE()
+	assert(t, errors.Is(err, ErrLossy))
+	u, err = Parse("18446744073709551615.0").UintE()
+	assert(t, err == nil && u == math.MaxUint64)
+	u, err = Parse("-0").UintE()
+	assert(t, err == nil && u == 0)
+	_, err = Get(jsonStr, "obj").UintE()
+	assert(t, errors.Is(err, ErrType))
+
+	f, err := Get(jsonStr, "frac").FloatE()
+	assert(t, err == nil && f == 1.5)
+	f, err = Get(jsonStr, "str").FloatE()
+	assert(t, err == nil && f == 42)
+	_, err = Get(jsonStr, "huge").FloatE()
+	assert(t, errors.Is(err, ErrRange))
+	_, err = Get(jsonStr, "word").FloatE()
+	assert(t, errors.Is(err, ErrType))
+
+	b, err := Get(jsonStr, "yes").BoolE()
+	assert(t, err == nil && b)
+	_, err = Get(jsonStr, "zero").BoolE()
+	assert(t, errors.Is(err, ErrType))
+
+	tm, err := Get(jsonStr, "time").TimeE()
+	assert(t, err == nil && tm.Year() == 2022)
+	_, err = Get(jsonStr, "bad").TimeE()
+	assert(t, errors.Is(err, ErrType))
+	_, err = Get(jsonStr, "missing").TimeE()
+	assert(t, errors.Is(err, ErrNotExist))
+
+	assert(t, Get(jsonStr, "neg").MustInt() == -12)
+	assert(t, Get(jsonStr, "yes").MustBool())
+	func() {
+		defer func() {
+			assert(t, errors.Is(recover().(error), ErrNotExist))
+		}()
+		Get(jsonStr, "missing").MustFloat()
+	}()
+}
+
+// 测试任意精度的数字
+func TestBigNumbers(t *testing.T) {
+	jsonStr := `{"id":123456789012345678901234567890,"amount":"19.990",
+		"exp":1.5e3,"tiny":-1.25e-4,"zero":-0.0,"frac":0.5,"word":"abc",
+		"huge":1e99999999}`
+
+	n, err := Get(jsonStr, "id").BigInt()
+	assert(t, err == nil && n.String() == "123456789012345678901234567890")
+	n, err = Get(jsonStr, "exp").BigInt()
+	assert(t, err == nil && n.String() == "1500")
+	_, err = Get(jsonStr, "frac").BigInt()
+	assert(t, errors.Is(err, ErrLossy))
+	_, err = Get(jsonStr, "word").BigInt()
+	assert(t, errors.Is(err, ErrType))
+	_, err = Get(jsonStr, "missing").BigInt()
+	assert(t, errors.Is(err, ErrNotExist))
+	_, err = Get(jsonStr, "huge").BigInt()
+	assert(t, errors.Is(err, ErrRange))
+
+	f, err := Get(jsonStr, "amount").BigFloat()
+	assert(t, err == nil && f.Text('f', 2) == "19.99")
+
+	for path, expect := range map[string]string{
+		"id":     "123456789012345678901234567890",
+		"amount": "19.99",
+		"exp":    "1500",
+		"tiny":   "-0.000125",
+		"zero":   "0",
+		"frac":   "0.5",
+	} {
+		d, err := Get(jsonStr, path).Decimal()
+		assert(t, err == nil && d == expect)
+	}
+
+	num, err := Get(jsonStr, "exp").JSONNumber()
+	assert(t, err == nil && num == "1.5e3")
+	num, err = Get(jsonStr, "amount").JSONNumber()
+	assert(t, err == nil && num == "19.990")
+	_, err = Get(jsonStr, "word").JSONNumber()
+	assert(t, errors.Is(err, ErrType))
+}
+
+// 测试过滤器中的精确数字比较
+func TestPreciseNumberQuery(t *testing.T) {
+	jsonStr := `[{"id":9007199254740993,"v":"a"},{"id":9007199254740992,"v":"b"},
+		{"id":0.30000000000000001,"v":"c"},{"id":1e2,"v":"d"}]`
+	assert(t, Get(jsonStr, `#(id==9007199254740993)#.v`).Raw == `["a","b"]`)
+
+	e := NewEngine(&Options{PreciseNumbers: true})
+	assert(t, e.Get(jsonStr, `#(id==9007199254740993)#.v`).Raw == `["a"]`)
+	assert(t, e.Get(jsonStr, `#(id>9007199254740992)#.v`).Raw == `["a"]`)
+	assert(t, e.Get(jsonStr, `#(id<0.3)#.v`).Raw == `[]`)
+	assert(t, e.Get(jsonStr, `#(id==100)#.v`).Raw == `["d"]`)
+	assert(t, e.Get(jsonStr, `#(id!=100)#.v`).Raw == `["a","b","c"]`)
+	assert(t, !DefaultEngine().Options().PreciseNumbers)
+}
+
+// 测试编译查询路径
+func TestCompile(t *testing.T) {
+	paths := []string{
+		"name.last", "friends.#.first", "friends.#(last=Murphy)#.first",
+		"friends.#(nets.#(==\"fb\"))#.first|@reverse", `fav\.movie`,
+		"{name.first,age,\"kids\":children|@reverse}", "[age,name.last].1",
+		"@this.age", "!true", "children.@reverse", "..0", "friends.1.nets|#",
+		"friends.#(age>45)#|#", "friends.#.nets|@flatten",
+	}
+	for _, path := range paths {
+		p, err := Compile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		assert(t, p.String() == path)
+		expect := Get(readmeJSON, path)
+		res := p.Get(readmeJSON)
+		if res.Raw != expect.Raw || res.Index != expect.Index {
+			t.Fatalf("%s: expected '%v', got '%v'", path, expect.Raw, res.Raw)
+		}
+		assert(t, p.GetBytes([]byte(readmeJSON)).Raw == expect.Raw)
+	}
+
+	for _, path := range []string{
+		"", "friends.#(last=Murphy", "{name.first,age", "@unknown",
+		"friends|@nope", "friends.#(nets|@nope)",
+	} {
+		_, err := Compile(path)
+		assert(t, errors.Is(err, ErrInvalidPath))
+	}
+
+	p := MustCompile("friends.#(age>45)#.first")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if res := p.Get(readmeJSON); res.Raw != `["Roger","Jane"]` {
+					t.Errorf("expected '%v', got '%v'", `["Roger","Jane"]`, res.Raw)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// 测试一次遍历提取多个路径
+func TestMultiPath(t *testing.T) {
+	paths := []string{
+		"name", "name.first", "name.last", "age", "children", "children.#",
+		"children.0", "children.2", "children.5", `fav\.movie`, "fav.movie",
+		"friends.#", "friends.#.first", "friends.#.nets", "friends.#.nets.#",
+		"friends.#.nets.0", "friends.1", "friends.1.nets.1", "friends.#.age",
+		"friends.#.nets.#.x", "f*.#.last", "na?e.f*", "missing", "missing.#",
+		"friends.#(last=Murphy)#.first", "friends|#", "@this.age",
+		"{name.first,age}", "..0", "#", "name.#", "name.last.x",
+	}
+	docs := []string{readmeJSON, basicJSON, complicatedJSON, `[1,[2,3],{"a":4}]`,
+		`{"a":{"b":1},"a":{"b":2,"c":3}}`, `{"#":{"x":1},"1":"one"}`, `"str"`,
+		`[{"#":[1,2]},[[1],[2,3]]]`, ``}
+	extra := []string{"#.#", "#.a", "1.1", "#.#.#", "a.b", "a.c", "#.x", "1"}
+	for _, doc := range docs {
+		all := append(append([]string{}, paths...), extra...)
+		m := MustCompileMany(all...)
+		results := m.Get(doc)
+		bresults := m.GetBytes([]byte(doc))
+		many := GetMany(doc, all...)
+		for i, path := range all {
+			expect := Get(doc, path)
+			for _, res := range []Result{results[i], bresults[i], many[i]} {
+				if res.Raw != expect.Raw || res.Type != expect.Type ||
+					res.Str != expect.Str || res.Index != expect.Index ||
+					fmt.Sprint(res.Indexes) != fmt.Sprint(expect.Indexes) {
+					t.Fatalf("%s: expected %#v, got %#v", path, expect, res)
+				}
+			}
+		}
+	}
+	_, err := CompileMany("name", "friends.#(last")
+	assert(t, errors.Is(err, ErrInvalidPath))
+}
+
+// 测试独立的查询引擎
+func TestEngine(t *testing.T) {
+	e := NewEngine(nil)
+	e.AddModifier("upper", func(json, arg string) string {
+		return strings.ToUpper(json)
+	})
+	assert(t, e.ModifierExists("upper"))
+	assert(t, !ModifierExists("upper", nil))
+	assert(t, e.Get(readmeJSON, "children|@upper").Raw == `["SARA","ALEX","JACK"]`)
+	assert(t, Get(readmeJSON, "children|@upper").Raw == ``)
+	assert(t, e.Get(readmeJSON, "friends.#(last=Murphy)#.first|@upper").Raw ==
+		`["DALE","JANE"]`)
+	assert(t, e.GetMany(readmeJSON, "age", "name.first|@upper")[1].Raw == `"TOM"`)
+	p, err := e.Compile("{a:children.0|@upper}")
+	assert(t, err == nil && p.Get(readmeJSON).Raw == `{"a":"SARA"}`)
+	_, err = Compile("children|@upper")
+	assert(t, errors.Is(err, ErrInvalidPath))
+	e.RemoveModifier("upper")
+	assert(t, !e.ModifierExists("upper"))
+	assert(t, e.Get(readmeJSON, "children|@reverse|0").Raw == `"Jack"`)
+
+	e = NewEngine(&Options{DisableModifiers: true, DisableStatic: true})
+	assert(t, e.Get(`{"@this":1,"!true":2}`, "@this").Raw == `1`)
+	assert(t, e.Get(`{"@this":1,"!true":2}`, "!true").Raw == `2`)
+	assert(t, e.Options().DisableStatic)
+	root := `{"a":[1,2]}`
+	assert(t, e.Path(Parse(root), root) == "")
+	assert(t, Parse(root).Path(root) == "@this")
+	assert(t, strings.Join(e.Paths(e.Get(root, "a.#(>0)#"), root), ",") == "a.0,a.1")
+
+	e = NewEngine(&Options{PreciseNumbers: true})
+	assert(t, e.Get(`[9007199254740993,9007199254740992]`,
+		`#(==9007199254740993)#`).Raw == `[9007199254740993]`)
+
+	// the paths of @sort and @groupby are evaluated by the same engine
+	e = NewEngine(nil)
+	e.AddModifier("len", func(json, arg string) string {
+		return strconv.Itoa(len(Parse(json).String()))
+	})
+	list := `[{"k":"ccc"},{"k":"a"},{"k":"bb"}]`
+	assert(t, e.Get(list, `@sort:{"by":"k|@len"}|#.k`).Raw == `["a","bb","ccc"]`)
+	assert(t, Get(list, `@sort:{"by":"k|@len"}|#.k`).Raw == `["ccc","a","bb"]`)
+	assert(t, e.Get(list, `@groupby:"k|@len"|@keys`).Raw == `["3","1","2"]`)
+	assert(t, Get(list, `@groupby:"k|@len"`).Raw == `{}`)
+	e = NewEngine(&Options{DisableRegex: true})
+	regexList := `[{"k":["ab"]},{"k":["a"]}]`
+	assert(t, Get(regexList, `@sort:{"by":"k.#(~=\"b\")"}|#.k.0`).Raw == `["a","ab"]`)
+	assert(t, e.Get(regexList, `@sort:{"by":"k.#(~=\"b\")"}|#.k.0`).Raw == `["ab","a"]`)
+
+	// registering modifiers while querying must not race
+	e = NewEngine(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				e.AddModifier(fmt.Sprintf("m%d_%d", i, j), modThis)
+				e.Get(readmeJSON, fmt.Sprintf("name|@m%d_%d", i, j))
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert(t, e.ModifierExists("m3_99"))
+}
+
+// 测试过滤器中的布尔表达式、类型判断和成员判断
+func TestQueryExpressions(t *testing.T) {
+	tests := []struct{ path, expect string }{
+		{`friends.#(age>45 && last=="Murphy")#.first`, `["Jane"]`},
+		{`friends.#(age<45 || first=="Roger")#.first`, `["Dale","Roger"]`},
+		{`friends.#(!(last=="Murphy"))#.first`, `["Roger"]`},
+		{`friends.#(!!(last=="Craig"))#.first`, `["Roger"]`},
+		{`friends.#((age>45 || age<45) && nets.#(=="ig"))#.first`,
+			`["Dale","Jane"]`},
+		{`friends.#(age is number && first is string)#|#`, `3`},
+		{`friends.#(nets is array && !(nets is object))#|#`, `3`},
+		{`friends.#(first in ["Dale","Jane"])#.age`, `[44,47]`},
+		{`friends.#(age in [68,1])#.first`, `["Roger"]`},
+		{`friends.#(first<$last)#.first`, `["Dale","Jane"]`},
+		{`friends.#(first%"D*" || last%"C*")#.first`, `["Dale","Roger"]`},
+		{`friends.#(last=Murphy)#.first`, `["Dale","Jane"]`},
+		{`children.#(is string && %"*a*")#`, `["Sara","Jack"]`},
+		{`friends.#(nick is string || age>60).first`, `"Roger"`},
+	}
+	for _, tt := range tests {
+		res := Get(readmeJSON, tt.path)
+		if res.Raw != tt.expect {
+			t.Fatalf("%s: expected '%v', got '%v'", tt.path, tt.expect, res.Raw)
+		}
+		p, err := Compile(tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		assert(t, p.Get(readmeJSON).Raw == tt.expect)
+	}
+	assert(t, Get(`[{"a":1,"b":2},{"a":3,"b":3},{"a":5,"b":4}]`,
+		`#(a>=$b)#.a`).Raw == `[3,5]`)
+	assert(t, Get(`[{"a":null},{"a":true},{"a":"x"},{}]`,
+		`#(a is null || a is bool)#|#`).Raw == `2`)
+	// sub paths are written as $path or @.path, bare words are literals
+	spans := `[{"start":1,"end":5},{"start":7,"end":3},{"start":2,"stop":9}]`
+	assert(t, Get(spans, `#(start<$end)#.start`).Raw == `[1]`)
+	assert(t, Get(spans, `#(start<@.end)#.start`).Raw == `[1]`)
+	assert(t, Get(spans, `#(end<@.start)#.start`).Raw == `[7]`)
+	assert(t, Get(`[{"a":"b"},{"a":"c","b":"c"}]`, `#(a==b)#.a`).Raw == `["b"]`)
+	assert(t, Get(`[{"a":"b"},{"a":"c","b":"c"}]`, `#(a==@.b)#.a`).Raw == `["c"]`)
+	roles := `[{"role":"user","admin":"user"},{"role":"admin"}]`
+	assert(t, Get(roles, `#(role==admin)#.role`).Raw == `["admin"]`)
+	assert(t, Get(roles, `#(role=="admin")#.role`).Raw == `["admin"]`)
+	assert(t, Get(`[{"a":"@.b","b":1}]`, `#(a=="@.b")#|#`).Raw == `1`)
+	// unquoted values keep '&&' and '||' followed by a bare word
+	words := `[{"first":"a||b"},{"first":"a"},{"first":"x&&y"}]`
+	assert(t, Get(words, `#(first==a||b)#.first`).Raw == `["a||b"]`)
+	assert(t, Get(words, `#(first==x&&y)#.first`).Raw == `["x&&y"]`)
+	assert(t, Get(words, `#(first==a || first=="x&&y")#|#`).Raw == `2`)
+	// in compares the json types
+	mixed := `[{"a":1},{"a":"1"},{"a":true},{"a":"true"}]`
+	assert(t, Get(mixed, `#(a in [1])#|#`).Raw == `1`)
+	assert(t, Get(mixed, `#(a in ["1","true"])#.a`).Raw == `["1","true"]`)
+	assert(t, Get(mixed, `#(a in [true])#.a`).Raw == `[true]`)
+	// a query that is not an expression keeps its simple form
+	_, err := Compile(`friends.#(age>45 &&)`)
+	assert(t, err == nil)
+
+	for _, path := range []string{
+		`friends.#((age>45)`, `friends.#(age is int)`,
+		`friends.#(age in 1)`, `friends.#(age>45 && (last=="Murphy")`,
+		`friends.#(` + strings.Repeat("!(", 40) + `age>1` +
+			strings.Repeat(")", 40) + `)`,
+	} {
+		assert(t, !Get(readmeJSON, path).Exists())
+		_, err := Compile(path)
+		assert(t, errors.Is(err, ErrInvalidPath))
+	}
+}
+
+// 测试过滤器中的正则表达式运算符
+func TestQueryRegex(t *testing.T) {
+	tests := []struct{ path, expect string }{
+		{`friends.#(first~="^[DJ]a")#.first`, `["Dale","Jane"]`},
+		{`friends.#(first~=^R)#.first`, `["Roger"]`},
+		{`friends.#(first!~="^[DJ]a")#.first`, `["Roger"]`},
+		{`friends.#(last~="^murphy$")#.first`, `[]`},
+		{`friends.#(last~*="^murphy$")#.first`, `["Dale","Jane"]`},
+		{`friends.#(last!~*="MURPHY")#.first`, `["Roger"]`},
+		{`friends.#(last~="(?i)craig")#.first`, `["Roger"]`},
+		{`friends.#(age>45 && first~="e$")#.first`, `["Jane"]`},
+		{`friends.#(nets.#(~="^f"))#.first`, `["Dale","Roger"]`},
+		{`children.#(~="a$")#`, `["Sara"]`},
+		{`friends.#(age~="4")#.first`, `[]`},
+	}
+	for _, tt := range tests {
+		res := Get(readmeJSON, tt.path)
+		if res.Raw != tt.expect {
+			t.Fatalf("%s: expected '%v', got '%v'", tt.path, tt.expect, res.Raw)
+		}
+		p, err := Compile(tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		assert(t, p.Get(readmeJSON).Raw == tt.expect)
+	}
+	assert(t, Get(`[{"a":"~x"},{"a":"y"}]`, `#(a~="^~")#.a`).Raw == `["~x"]`)
+	assert(t, Get(`[{"a":true},{"a":false}]`, `#(a==~true)#.a`).Raw == `[true]`)
+	// a '~' that does not start a regex operator is part of the key
+	tilde := `[{"a~b":"x","n":1},{"a~b":"y","n":2},{"c":3}]`
+	assert(t, Get(tilde, `#(a~b=="y").n`).Raw == `2`)
+	assert(t, Get(tilde, `#(a~b)#.n`).Raw == `[1,2]`)
+	assert(t, Get(tilde, `#(a~b=="x" || a~b=="y")#.n`).Raw == `[1,2]`)
+	p, err := Compile(`#(a~b=="y").n`)
+	assert(t, err == nil && p.Get(tilde).Raw == `2`)
+
+	for _, path := range []string{
+		`friends.#(first~="(")`, `friends.#(age>1 || first~="[a-")`,
+	} {
+		assert(t, !Get(readmeJSON, path).Exists())
+		_, err := Compile(path)
+		assert(t, errors.Is(err, ErrInvalidPath))
+	}
+
+	e := NewEngine(&Options{DisableRegex: true})
+	assert(t, e.Get(readmeJSON, `friends.#(first~="^D")#.first`).Raw == `[]`)
+	assert(t, e.Get(readmeJSON, `friends.#(first!~="^D")#.first`).Raw == `[]`)
+	_, err = e.Compile(`friends.#(first~="^D")#.first`)
+	assert(t, errors.Is(err, ErrInvalidPath))
+	_, err = e.Compile(`friends.#(first%"D*")#.first`)
+	assert(t, err == nil)
+}
+
+// 测试聚合修饰符
+func TestAggregateModifiers(t *testing.T) {
+	tests := []struct{ path, expect string }{
+		{`friends.#.age|@sum`, `159`},
+		{`friends.#.age|@avg`, `53`},
+		{`friends.#.age|@min`, `44`},
+		{`friends.#.age|@max`, `68`},
+		{`friends.#.first|@max`, `"Roger"`},
+		{`friends|@count`, `3`},
+		{`name|@count`, `2`},
+		{`age|@count`, `1`},
+		{`friends.#.last|@distinct`, `["Murphy","Craig"]`},
+		{`friends.#.nets|@flatten|@distinct`, `["ig","fb","tw"]`},
+		{`children|@sort`, `["Alex","Jack","Sara"]`},
+		{`friends|@sort:{"by":"age","desc":true}|#.first`,
+			`["Roger","Jane","Dale"]`},
+		{`friends|@sort:{"by":"last"}|#.first`, `["Roger","Dale","Jane"]`},
+		{`friends|@groupby:last|@keys`, `["Murphy","Craig"]`},
+		{`friends|@groupby:"last"|Murphy.#.first`, `["Dale","Jane"]`},
+		{`friends.#(age>100)#|@sum`, `0`},
+		{`friends.#(age>100)#|@avg`, `null`},
+	}
+	for _, tt := range tests {
+		res := Get(readmeJSON, tt.path)
+		if res.Raw != tt.expect {
+			t.Fatalf("%s: expected '%v', got '%v'", tt.path, tt.expect, res.Raw)
+		}
+	}
+	assert(t, Get(`[9007199254740993,1,"x",null]`, `@sum`).Raw ==
+		`9007199254740994`)
+	assert(t, Get(`[12345678901234567890,12345678901234567890]`, `@avg`).Raw ==
+		`12345678901234567890`)
+	assert(t, Get(`[1,2,3,4]`, `@avg`).Raw == `2.5`)
+	assert(t, Get(`[1,2,0.5]`, `@sum`).Raw == `3.5`)
+	assert(t, Get(`[9007199254740993,9007199254740992]`, `@max`).Raw ==
+		`9007199254740993`)
+	assert(t, Get(`[1,1.0,1e0,"1",[1, 2],[1,2]]`, `@distinct`).Raw ==
+		`[1,"1",[1, 2]]`)
+	assert(t, Get(`["b",2,null,true,"a",1,false]`, `@sort`).Raw ==
+		`[null,false,true,1,2,"a","b"]`)
+	assert(t, Get(`[{"k":1},{"v":2},{"k":1.0}]`, `@groupby:k`).Raw ==
+		`{"1":[{"k":1},{"k":1.0}]}`)
+	assert(t, !Get(`{"a":1}`, `@sum`).Exists())
+}
+
+// 测试变换修饰符
+func TestTransformModifiers(t *testing.T) {
+	tests := []struct{ path, expect string }{
+		{`name|@pick:["first"]`, `{"first":"Tom"}`},
+		{`name|@pick:"last"`, `{"last":"Anderson"}`},
+		{`friends|@pick:["first","age"]|0`, `{"first":"Dale","age":44}`},
+		{`name|@omit:["first","nick"]`, `{"last":"Anderson"}`},
+		{`friends|@omit:["nets","age","last"]|#.first`,
+			`["Dale","Roger","Jane"]`},
+		{`name|@rename:{"first":"given","last":"family"}`,
+			`{"given":"Tom","family":"Anderson"}`},
+		{`friends|@rename:{"first":"name"}|#.name`, `["Dale","Roger","Jane"]`},
+		{`name|@ugly|@tostr`, `"{\"first\":\"Tom\",\"last\":\"Anderson\"}"`},
+		{`name|@ugly|@tostr|@fromstr|first`, `"Tom"`},
+		{`age|@default:0`, `37`},
+		{`missing|@default:0`, `0`},
+		{`missing|@default:{"a":1}|a`, `1`},
+		{`name.missing|@default:none`, `"none"`},
+		{`{"nick":nick|@default:"n/a",age}`, `{"nick":"n/a","age":37}`},
+		{`children|@pick:["a"]`, `["Sara","Alex","Jack"]`},
+	}
+	for _, tt := range tests {
+		res := Get(readmeJSON, tt.path)
+		if res.Raw != tt.expect {
+			t.Fatalf("%s: expected '%v', got '%v'", tt.path, tt.expect, res.Raw)
+		}
+	}
+	assert(t, !Get(`{"s":"{bad"}`, `s|@fromstr`).Exists())
+	assert(t, !Get(`{"s":1}`, `s|@fromstr`).Exists())
+	assert(t, !Get(`{}`, `missing|@tostr`).Exists())
+}
+
+const storeJSON = `{ "store": {
+    "book": [
+      { "category": "reference", "author": "Nigel Rees",
+        "title": "Sayings of the Century", "price": 8.95 },
+      { "category": "fiction", "author": "Evelyn Waugh",
+        "title": "Sword of Honour", "price": 12.99 },
+      { "category": "fiction", "author": "Herman Melville",
+        "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99 },
+      { "category": "fiction", "author": "J. R. R. Tolkien",
+        "title": "The Lord of the Rings", "isbn": "0-395-19395-8",
+        "price": 22.99 }
+    ],
+    "bicycle": { "color": "red", "price": 399 }
+  }
+}`
+
+// 测试RFC 9535 JSONPath表达式
+func TestJSONPath(t *testing.T) {
+	tests := []struct{ expr, expect string }{
+		{`$.store.book[*].author`, `["Nigel Rees","Evelyn Waugh",` +
+			`"Herman Melville","J. R. R. Tolkien"]`},
+		{`$..author`, `["Nigel Rees","Evelyn Waugh","Herman Melville",` +
+			`"J. R. R. Tolkien"]`},
+		{`$.store.*.color`, `["red"]`},
+		{`$.store..price`, `[8.95,12.99,8.99,22.99,399]`},
+		{`$..book[2].title`, `["Moby Dick"]`},
+		{`$..book[-1].title`, `["The Lord of the Rings"]`},
+		{`$..book[0,1].title`, `["Sayings of the Century","Sword of Honour"]`},
+		{`$..book[:2].price`, `[8.95,12.99]`},
+		{`$..book[::-2].price`, `[22.99,12.99]`},
+		{`$..book[1:3:1].price`, `[12.99,8.99]`},
+		{`$..book[?@.isbn].title`, `["Moby Dick","The Lord of the Rings"]`},
+		{`$..book[?(@.price<10)].title`,
+			`["Sayings of the Century","Moby Dick"]`},
+		{`$..book[?@.price<$.store.bicycle.price && @.category=='fiction']` +
+			`.price`, `[12.99,8.99,22.99]`},
+		{`$..book[?!(@.category == "fiction")].author`, `["Nigel Rees"]`},
+		{`$..book[?length(@.title) > 15].price`, `[8.95,22.99]`},
+		{`$..book[?match(@.author, 'J.*')].price`, `[22.99]`},
+		{`$..book[?search(@.title, 'of')].price`, `[8.95,12.99,22.99]`},
+		{`$.store[?count(@.*) == 2].color`, `["red"]`},
+		{`$.store.book[?value(@.isbn) == '0-553-21311-3'].price`, `[8.99]`},
+		{`$["store"]['bicycle']["color"]`, `["red"]`},
+		{`$.store.book[?@.missing == @.other].price`,
+			`[8.95,12.99,8.99,22.99]`},
+		{`$.store.bicycle[?@ == 'red']`, `["red"]`},
+		{`$..*[?@ > 300]`, `[399]`},
+		{`$.store.book[7]`, `[]`},
+		{`$`, `[` + storeJSON + `]`},
+	}
+	for _, tt := range tests {
+		res, err := JSONPath(storeJSON, tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		var raws []string
+		for _, r := range res {
+			raws = append(raws, r.Raw)
+			// each node points back into the json
+			assert(t, storeJSON[r.Index:r.Index+len(r.Raw)] == r.Raw)
+		}
+		got := "[" + strings.Join(raws, ",") + "]"
+		if got != tt.expect {
+			t.Fatalf("%s: expected '%v', got '%v'", tt.expr, tt.expect, got)
+		}
+	}
+
+	res, err := JSONPath(storeJSON, `$..book[?@.price > 20].title`)
+	assert(t, err == nil && len(res) == 1)
+	assert(t, res[0].Path(storeJSON) == "store.book.3.title")
+	assert(t, Get(storeJSON, res[0].Path(storeJSON)).Raw == res[0].Raw)
+
+	res, err = JSONPath(`[[1,2],{"a":[3]}]`, `$[?@[0] == 1 || @.a[0] == 3]`)
+	assert(t, err == nil && len(res) == 2)
+	res, err = JSONPath(`[{"a":[1,{"b":2}]},{"a":[1,{"b":3}]}]`,
+		`$[?@.a == $[0].a]`)
+	assert(t, err == nil && len(res) == 1 && res[0].Index == 1)
+
+	for _, expr := range []string{
+		``, `store`, `$.`, `$..`, `$[`, `$[1`, `$['a`, `$[01]`, `$[-0]`,
+		`$[9007199254740992]`, `$[?@.a == @.*]`, `$[?length(@.*) == 1]`,
+		`$[?count(1) == 1]`, `$[?match(@.a) ]`, `$[?nope(@)]`,
+		`$[?length(@)]`, `$[?@.a == 1 ==]`, `$[?1]`, `$[?(@.a]`,
+		`$ .a x`, `$['\x']`, `$["\uD800"]`, `$[?@.a == 01]`,
+	} {
+		_, err := JSONPath(storeJSON, expr)
+		if !errors.Is(err, ErrInvalidPath) {
+			t.Fatalf("%s: expected ErrInvalidPath, got %v", expr, err)
+		}
+	}
+}
+
+// 测试RFC 6901 JSON Pointer
+func TestPointer(t *testing.T) {
+	jsonStr := `{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,
+		"i\\j":5,"k\"l":6," ":7,"m~n":8,"o.p":{"*":9,"?":10}}`
+	tests := []struct{ ptr, expect string }{
+		{``, jsonStr}, {`/foo`, `["bar","baz"]`}, {`/foo/0`, `"bar"`},
+		{`/`, `0`}, {`/a~1b`, `1`}, {`/c%d`, `2`}, {`/e^f`, `3`},
+		{`/g|h`, `4`}, {`/i\j`, `5`}, {`/k"l`, `6`}, {`/ `, `7`},
+		{`/m~0n`, `8`}, {`/o.p/*`, `9`}, {`/o.p/?`, `10`},
+	}
+	for _, tt := range tests {
+		res := GetPointer(jsonStr, tt.ptr)
+		if res.Raw != tt.expect {
+			t.Fatalf("%s: expected '%v', got '%v'", tt.ptr, tt.expect, res.Raw)
+		}
+		ptr, ok := res.Pointer(jsonStr)
+		assert(t, ok && ptr == tt.ptr)
+		if path, err := PointerToPath(tt.ptr); err == nil {
+			assert(t, Get(jsonStr, path).Raw == tt.expect)
+			back, err := PathToPointer(path)
+			assert(t, err == nil && back == tt.ptr)
+		}
+	}
+	for _, ptr := range []string{
+		`foo`, `/foo/2`, `/foo/-`, `/foo/01`, `/foo/a`, `/m~2n`, `/m~`,
+		`/missing/0`,
+	} {
+		assert(t, !GetPointer(jsonStr, ptr).Exists())
+	}
+
+	path, err := PointerToPath(`/o.p/*`)
+	assert(t, err == nil && path == `o\.p.\*`)
+	path, err = PointerToPath(`/m~0n/a~1b/0`)
+	assert(t, err == nil && path == `m\~n.a\/b.0`)
+	_, err = PointerToPath(`/`)
+	assert(t, errors.Is(err, ErrInvalidPath))
+	for _, path := range []string{
+		"", "a.*", "a?", "friends.#", "a|b", "@reverse", "a..b", "a.", `a\`,
+	} {
+		_, err := PathToPointer(path)
+		assert(t, errors.Is(err, ErrInvalidPath))
+	}
+
+	res := Get(readmeJSON, "friends.1.nets.0")
+	ptr, ok := res.Pointer(readmeJSON)
+	assert(t, ok && ptr == "/friends/1/nets/0")
+	assert(t, GetPointer(readmeJSON, ptr).Raw == `"fb"`)
+	assert(t, GetPointerBytes([]byte(readmeJSON), "/fav.movie").Str ==
+		"Deer Hunter")
+}
+
+// 测试任意深度的递归搜索
+func TestDeepPath(t *testing.T) {
+	jsonStr := `{"id":1,"a":{"id":2,"b":[{"id":3},{"c":{"id":4}}]},
+		"d":[[{"id":5}]],"e":{"x.id":6,"tags":["t1","t2"]}}`
+	tests := []struct{ path, expect string }{
+		{`**.id`, `[1,2,3,4,5]`},
+		{`a..id`, `[2,3,4]`},
+		{`a.**.id`, `[2,3,4]`},
+		{`a.b..id`, `[3,4]`},
+		{`**.id|@sum`, `15`},
+		{`**.id|#`, `5`},
+		{`**.c.id`, `[4]`},
+		{`**.i?`, `[1,2,3,4,5]`},
+		{`**.x\.id`, `[6]`},
+		{`**.tags.0`, `["t1"]`},
+		{`**.#(id>=3)`, `[{"id":3},{"id":5}]`},
+		{`**.nope`, `[]`},
+		{`nope..id`, ``},
+		{`{"ids":**.id}`, `{"ids":[1,2,3,4,5]}`},
+	}
+	for _, tt := range tests {
+		res := Get(jsonStr, tt.path)
+		if res.Raw != tt.expect {
+			t.Fatalf("%s: expected '%v', got '%v'", tt.path, tt.expect, res.Raw)
+		}
+		p, err := Compile(tt.path)
+		assert(t, err == nil && p.Get(jsonStr).Raw == tt.expect)
+		assert(t, GetMany(jsonStr, tt.path)[0].Raw == tt.expect)
+	}
+
+	res := Get(jsonStr, "a..id")
+	assert(t, len(res.Indexes) == 3)
+	res.ForEach(func(_, value Result) bool {
+		assert(t, jsonStr[value.Index:value.Index+len(value.Raw)] == value.Raw)
+		return true
+	})
+	assert(t, strings.Join(res.Paths(jsonStr), " ") ==
+		"a.id a.b.0.id a.b.1.c.id")
+	res = Get(readmeJSON, "friends.#.nets..0")
+	assert(t, res.Raw == `["ig","fb","ig"]`)
+	assert(t, strings.Join(res.Paths(readmeJSON), " ") ==
+		"friends.0.nets.0 friends.1.nets.0 friends.2.nets.0")
+
+	// a leading '..' is still the JSON Lines mode
+	assert(t, Get("{\"id\":1}\n{\"id\":2}", "..#.id").Raw == `[1,2]`)
+	// '**' as a key wildcard is unchanged at the end of a path
+	assert(t, Get(`{"a":{"b":1}}`, "a.**").Raw == `1`)
+	// an escaped '.' does not start a '**' component
+	assert(t, Get(`{"a.**":{"b":1},"a":{"x":{"b":2}}}`, `a\.**.b`).Raw == `1`)
+	assert(t, Get(`{"a.**":{"b":1},"a":{"x":{"b":2}}}`, `a.**.b`).Raw == `[2]`)
+
+	// the elements of a '#' projection are searched
+	items := `{"items":[{"price":1,"sub":{"price":2}},{"price":3}],"price":9}`
+	for _, path := range []string{"items.#..price", "items.#.**.price"} {
+		res = Get(items, path)
+		assert(t, res.Raw == `[1,2,3]`)
+		assert(t, strings.Join(res.Paths(items), " ") ==
+			"items.0.price items.0.sub.price items.1.price")
+		p := MustCompile(path)
+		assert(t, p.Get(items).Raw == `[1,2,3]`)
+	}
+	assert(t, Get(`[{"a":1},{"b":{"a":2}}]`, "#..a").Raw == `[1,2]`)
+
+	// compiled paths keep the split parts
+	p := MustCompile(`a.b..id|@sum`)
+	assert(t, p.deep != nil && p.deep.base != nil && p.deep.leftPath != nil &&
+		p.deep.rightPath != nil)
+	assert(t, p.Get(jsonStr).Raw == `7`)
+	assert(t, MustCompile(`a.b.id`).deep == nil)
+}
+
+// 测试负数索引和数组切片
+func TestArraySlices(t *testing.T) {
+	tests := []struct{ path, expect string }{
+		{`children.-1`, `"Jack"`},
+		{`children.-3`, `"Sara"`},
+		{`children.-4`, ``},
+		{`friends.-1.first`, `"Jane"`},
+		{`friends.-2|first`, `"Roger"`},
+		{`children.[0:2]`, `["Sara","Alex"]`},
+		{`children.[1:]`, `["Alex","Jack"]`},
+		{`children.[:-1]`, `["Sara","Alex"]`},
+		{`children.[-2:]`, `["Alex","Jack"]`},
+		{`children.[::2]`, `["Sara","Jack"]`},
+		{`children.[::-1]`, `["Jack","Alex","Sara"]`},
+		{`children.[5:9]`, `[]`},
+		{`children.[0:3:0]`, `[]`},
+		{`friends.[1:3].first`, `["Roger","Jane"]`},
+		{`friends.[0:2].first|@reverse`, `["Roger","Dale"]`},
+		{`friends.[0:2]|#`, `2`},
+		{`friends.[:2].nets.-1`, `["tw","tw"]`},
+		{`friends.#.nets.[0:1]`, `[["ig"],["fb"],["ig"]]`},
+		{`friends.#.nets.-1`, `["tw","tw","tw"]`},
+		{`friends.#(age>45)#|[0:1]`, `[{"first": "Roger", "last": "Craig", ` +
+			`"age": 68, "nets": ["fb", "tw"]}]`},
+		{`friends.#.first|-1`, `"Jane"`},
+		{`{"last":children.-1,"two":children.[:2]}`,
+			`{"last":"Jack","two":["Sara","Alex"]}`},
+		{`[name.first,age]`, `["Tom",37]`},
+		{`name.-1`, ``},
+	}
+	for _, tt := range tests {
+		res := Get(readmeJSON, tt.path)
+		if res.Raw != tt.expect {
+			t.Fatalf("%s: expected '%v', got '%v'", tt.path, tt.expect, res.Raw)
+		}
+		p, err := Compile(tt.path)
+		assert(t, err == nil && p.Get(readmeJSON).Raw == tt.expect)
+		assert(t, GetMany(readmeJSON, tt.path)[0].Raw == tt.expect)
+	}
+	assert(t, Get(`[1,2,3,4,5]`, `[1:4]`).Raw == `[2,3,4]`)
+	assert(t, Get(`[1,2,3,4,5]`, `-1`).Raw == `5`)
+	assert(t, Get(`{"-1":"key"}`, `-1`).Raw == `"key"`)
+
+	res := Get(readmeJSON, "friends.[1:].first")
+	assert(t, len(res.Indexes) == 2)
+	assert(t, strings.Join(res.Paths(readmeJSON), " ") ==
+		"friends.1.first friends.2.first")
+	res = Get(readmeJSON, "friends.-1.age")
+	assert(t, res.Path(readmeJSON) == "friends.2.age")
+
+	// '#' applies to the sliced array
+	assert(t, Get(readmeJSON, "friends.[1:].#").Raw == `2`)
+	res = Get(readmeJSON, "friends.[1:].#.first")
+	assert(t, res.Raw == `["Roger","Jane"]`)
+	assert(t, strings.Join(res.Paths(readmeJSON), " ") ==
+		"friends.1.first friends.2.first")
+	res = Get(readmeJSON, "friends.[::-2].#(age>45)#.first")
+	assert(t, res.Raw == `["Jane"]`)
+	assert(t, res.Paths(readmeJSON)[0] == "friends.2.first")
+	assert(t, MustCompile("friends.[1:].#").Get(readmeJSON).Raw == `2`)
+
+	// a projection of slices has the offsets of the sliced arrays
+	res = Get(readmeJSON, "friends.#.nets.[-1:]")
+	assert(t, res.Raw == `[["tw"],["tw"],["tw"]]`)
+	for i, index := range res.Indexes {
+		nets := Get(readmeJSON, fmt.Sprintf("friends.%d.nets", i))
+		assert(t, index == nets.Index)
+	}
+	res = Get(readmeJSON, "children.[0:2]")
+	assert(t, res.Index == Get(readmeJSON, "children").Index)
+}
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		opts  Options
+		json  string
+		limit string
+	}{
+		{Options{MaxDepth: 2}, `{"a":[{"b":1}]}`, "MaxDepth 2 exceeded at offset 6"},
+		{Options{MaxDepth: 3}, `{"a":[{"b":1}]}`, ""},
+		{Options{MaxBytes: 8}, `{"a":"bc"}`, "MaxBytes"},
+		{Options{MaxStringLength: 3}, `{"a":"b\"cd"}`, "MaxStringLength 3 exceeded at offset 5"},
+		{Options{MaxStringLength: 3}, `{"abcd":1}`, "MaxStringLength"},
+		{Options{MaxStringLength: 4}, `["\u00e9\u00e9","\ud83d\ude00"]`, ""},
+		{Options{MaxStringLength: 3}, `["\u00e9\u00e9"]`, "MaxStringLength 3 exceeded at offset 1"},
+		{Options{MaxStringLength: 4}, `["\ud800\u0041","\ud800\n"]`, ""},
+		{Options{MaxStringLength: 3}, `["\ud800\u0041"]`, "MaxStringLength"},
+		{Options{MaxNumberLength: 3}, `[1,-1e10]`, "MaxNumberLength 3 exceeded at offset 3"},
+		{Options{MaxNumberLength: 3}, `[-100,1e10]`, "MaxNumberLength 3 exceeded at offset 6"},
+		{Options{MaxArrayElements: 2}, `[[1,2],[3,4,5]]`, "MaxArrayElements 2 exceeded at offset 11"},
+		{Options{MaxArrayElements: 2}, `[[],[1,2],{"a":[]}]`, "MaxArrayElements 2 exceeded at offset 9"},
+		{Options{MaxObjectKeys: 1}, `[{"a":1},{"a":[1,2,3],"b":2}]`, "MaxObjectKeys 1 exceeded at offset 21"},
+		{Options{MaxObjectKeys: 1, MaxArrayElements: 3}, `{"a":[1,2,3]}`, ""},
+	}
+	for _, tt := range tests {
+		e := NewEngine(&tt.opts)
+		err := e.CheckLimits(tt.json)
+		if tt.limit == "" {
+			assert(t, err == nil)
+			assert(t, e.Get(tt.json, "@this").Raw == tt.json)
+			res, err := e.GetE(tt.json, "@this")
+			assert(t, err == nil && res.Raw == tt.json)
+			continue
+		}
+		if !errors.Is(err, ErrLimitExceeded) || !strings.Contains(err.Error(), tt.limit) {
+			t.Fatalf("%s: expected %q, got %v", tt.json, tt.limit, err)
+		}
+		var lerr *LimitError
+		assert(t, errors.As(err, &lerr) && strings.HasPrefix(tt.limit, lerr.Limit))
+		assert(t, !e.Get(tt.json, "@this").Exists())
+		assert(t, !e.GetBytes([]byte(tt.json), "@this").Exists())
+		assert(t, !e.GetMany(tt.json, "@this")[0].Exists())
+		p, err := e.Compile("@this")
+		assert(t, err == nil && !p.Get(tt.json).Exists())
+
+		_, err = e.GetE(tt.json, "@this")
+		assert(t, errors.As(err, &lerr) && err.Error() == lerr.Error())
+		_, err = e.GetBytesE([]byte(tt.json), "@this")
+		assert(t, errors.Is(err, ErrLimitExceeded))
+		many, err := e.GetManyE(tt.json, "@this")
+		assert(t, many == nil && errors.Is(err, ErrLimitExceeded))
+		_, err = p.GetE(tt.json)
+		assert(t, errors.Is(err, ErrLimitExceeded))
+		_, err = p.GetBytesE([]byte(tt.json))
+		assert(t, errors.Is(err, ErrLimitExceeded))
+	}
+	many, err := NewEngine(nil).GetManyE(`{"a":1}`, "a", "b")
+	assert(t, err == nil && many[0].Raw == "1" && !many[1].Exists())
+	assert(t, DefaultEngine().CheckLimits(`[[[[[[1]]]]]]`) == nil)
+}
+
+func TestValidStrict(t *testing.T) {
+	tests := []struct {
+		json   string
+		expect string
+	}{
+		{`{"a":1,"b":{"a":2}}`, ""},
+		{`[{"a":1},{"a":2}]`, ""},
+		{`{"a":1,"a":2}`, "query: duplicate key: a at offset 7"},
+		{` {"a":[0,{"x.y":1,"x.y":2}]}`, `query: duplicate key: a.1.x\.y at offset 18`},
+		{`{"a":{"b":1},"a":{"b":1}}`, "query: duplicate key: a at offset 13"},
+		{`{"a":1,}`, "query: invalid json at offset 7"},
+		{`["\ud83d\ude00","é",1e308,-1e-400]`, ""},
+		{"[\"ok\",\"a\xffb\"]", "query: invalid UTF-8 at offset 8"},
+		{`["a\ud800b"]`, "query: lone surrogate at offset 3"},
+		{`["\udc00\ud800"]`, "query: lone surrogate at offset 2"},
+		{`["\ud800\u0041"]`, "query: lone surrogate at offset 2"},
+		{`{"n":[1,-1e309]}`, "query: number out of range at offset 8"},
+	}
+	for _, tt := range tests {
+		err := ValidStrict(tt.json)
+		if tt.expect == "" {
+			assert(t, err == nil)
+			continue
+		}
+		if err == nil || err.Error() != tt.expect {
+			t.Fatalf("%s: expected %q, got %v", tt.json, tt.expect, err)
+		}
+		assert(t, ValidStrictBytes([]byte(tt.json)).Error() == tt.expect)
+	}
+	assert(t, errors.Is(ValidStrict(`{"a":1,"a":1}`), ErrDuplicateKey))
+	assert(t, errors.Is(ValidStrict(`{`), ErrInvalidJSON))
+}
+
+func TestRedact(t *testing.T) {
+	json := `{"user":{"name":"Tom","password":"secret","cards":[` +
+		`{"no":"4111","cvv":123},{"no":"5500","cvv":456}]},"token":"abc"}`
+	tests := []struct {
+		paths  []string
+		expect string
+	}{
+		{[]string{"user.password", "token"}, `{"user":{"name":"Tom","password":"[REDACTED]","cards":[` +
+			`{"no":"4111","cvv":123},{"no":"5500","cvv":456}]},"token":"[REDACTED]"}`},
+		{[]string{"user.cards.#.cvv"}, `{"user":{"name":"Tom","password":"secret","cards":[` +
+			`{"no":"4111","cvv":"[REDACTED]"},{"no":"5500","cvv":"[REDACTED]"}]},"token":"abc"}`},
+		{[]string{"**.no", "user.cards"}, `{"user":{"name":"Tom","password":"secret",` +
+			`"cards":"[REDACTED]"},"token":"abc"}`},
+		{[]string{"missing", "user.name|@reverse"}, json},
+	}
+	for _, tt := range tests {
+		if got := Redact(json, tt.paths...); got != tt.expect {
+			t.Fatalf("%v: expected %s, got %s", tt.paths, tt.expect, got)
+		}
+	}
+	assert(t, RedactWith(json, `null`, "user.name") ==
+		strings.Replace(json, `"Tom"`, `null`, 1))
+	assert(t, string(RedactBytes([]byte(`[1,2,3]`), "1")) == `[1,"[REDACTED]",3]`)
+
+	// '#' inside of a projection
+	nested := `{"list":[[{"s":1},{"s":2,"t":0}],[],[{"t":3},{"s":4}]]}`
+	res := Get(nested, "list.#.#.s")
+	assert(t, len(res.Indexes) == 3 && res.Indexes[0] == 0 && res.Indexes[2] == 0)
+	assert(t, Redact(nested, "list.#.#.s") ==
+		`{"list":[[{"s":"[REDACTED]"},{"s":"[REDACTED]","t":0}],[],[{"t":3},{"s":"[REDACTED]"}]]}`)
+	assert(t, Redact(`[[{"s":1}],[{"s":2}]]`, "#.#.s") == `[[{"s":"[REDACTED]"}],[{"s":"[REDACTED]"}]]`)
+	assert(t, Redact(`{"a":[{"b":[{"c":[1,2]}]},{"b":[]}]}`, "a.#.b.#.c.#") ==
+		`{"a":[{"b":[{"c":[1,2]}]},{"b":[]}]}`)
+	assert(t, Redact(nested, `list.#.#(t>1)#`) == `{"list":[[{"s":1},{"s":2,"t":0}],[],["[REDACTED]",{"s":4}]]}`)
+
+	// the paths that could not be located are reported
+	out, err := RedactChecked(json, `null`, "token", "missing", "user.name|@reverse", "user.cards.#")
+	assert(t, errors.Is(err, ErrNotLocated))
+	assert(t, strings.Contains(err.Error(), `"user.name|@reverse"`) &&
+		strings.Contains(err.Error(), `"user.cards.#"`) && !strings.Contains(err.Error(), "missing"))
+	assert(t, out == strings.Replace(json, `"abc"`, `null`, 1))
+	out, err = RedactChecked(nested, `0`, "list.#.#.s", "list.2.0.t")
+	assert(t, err == nil && out == `{"list":[[{"s":0},{"s":0,"t":0}],[],[{"t":0},{"s":0}]]}`)
+	_, err = RedactChecked(json, `0`, "user.cards.#.no|@reverse")
+	assert(t, errors.Is(err, ErrNotLocated))
+}
@@ -0,0 +1,183 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// RedactedValue 是 Redact 写入的替换值。
+const RedactedValue = `"[REDACTED]"`
+
+// ErrNotLocated 表示路径的值存在，但不能在原始json中定位，因此没有被替换，
+// 例如修饰符或管道的结果。使用 errors.Is 检查。
+var ErrNotLocated = errors.New("query: value cannot be located")
+
+// Redact 将json中与路径匹配的值替换为 "[REDACTED]"，返回新的json。
+// 路径使用 Get 的语法，包含 '#' 查询或 '**' 的路径会替换所有匹配的值。
+// 不存在的路径会被忽略；值不能在原始json中定位的路径（例如修饰符的结果）
+// 也会被忽略，需要知道这些路径时请使用 RedactChecked。
+func Redact(json string, paths ...string) string {
+	return RedactWith(json, RedactedValue, paths...)
+}
+
+// RedactBytes 与 Redact 相同，但使用字节切片作为输入。
+func RedactBytes(json []byte, paths ...string) []byte {
+	return []byte(Redact(bytesString(json), paths...))
+}
+
+// RedactWith 与 Redact 相同，但使用指定的替换值，替换值会原样写入，
+// 因此必须是有效的json，例如 `"***"` 或 `null`。
+func RedactWith(json, replacement string, paths ...string) string {
+	out, _ := redact(json, replacement, paths)
+	return out
+}
+
+// RedactChecked 与 RedactWith 相同，但值不能在原始json中定位的路径会
+// 返回包装了 ErrNotLocated 的错误，错误中列出这些路径。
+// 其余路径的值仍然会被替换。
+func RedactChecked(json, replacement string, paths ...string) (string, error) {
+	out, skipped := redact(json, replacement, paths)
+	if len(skipped) > 0 {
+		return out, fmt.Errorf("%w: %q", ErrNotLocated, skipped)
+	}
+	return out, nil
+}
+
+type redactSpan struct{ start, end int }
+
+// redact replaces the values of the paths, it returns the paths whose
+// values exist but could not be found at their place in the json
+func redact(json, replacement string, paths []string) (string, []string) {
+	var spans []redactSpan
+	var skipped []string
+	for _, path := range paths {
+		if !locateValues(json, path, &spans) {
+			skipped = append(skipped, path)
+		}
+	}
+	if len(spans) == 0 {
+		return json, skipped
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+	out := make([]byte, 0, len(json))
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			continue // inside a value that is already replaced
+		}
+		out = append(out, json[last:s.start]...)
+		out = append(out, replacement...)
+		last = s.end
+	}
+	out = append(out, json[last:]...)
+	return string(out), skipped
+}
+
+// locateValues appends the spans of the values of path, it returns false
+// when some of them could not be located
+func locateValues(json, path string, spans *[]redactSpan) bool {
+	res := Get(json, path)
+	if !res.Exists() {
+		return true
+	}
+	found, ok := spansOf(json, res)
+	*spans = append(*spans, found...)
+	if ok {
+		return true
+	}
+	// a '#' inside of a projection has no indexes, each element of the
+	// array is located with its own path instead
+	prefix, rest, ok := splitProjection(path)
+	if !ok {
+		return false
+	}
+	arr := Parse(json)
+	if prefix != "" {
+		arr = Get(json, prefix)
+	}
+	if _, ok := spansOf(json, arr); !ok || !arr.IsArray() {
+		return false
+	}
+	located := true
+	for i := range arr.Array() {
+		elem := strconv.Itoa(i)
+		if prefix != "" {
+			elem = prefix + "." + elem
+		}
+		if !locateValues(json, elem+"."+rest, spans) {
+			located = false
+		}
+	}
+	return located
+}
+
+// spansOf returns the spans of the values of res that are at their place
+// in the json, ok is false when some of them are not
+func spansOf(json string, res Result) ([]redactSpan, bool) {
+	at := func(index int, raw string) (redactSpan, bool) {
+		if raw != "" && index >= 0 && index+len(raw) <= len(json) &&
+			json[index:index+len(raw)] == raw && (index > 0 || raw == json) {
+			return redactSpan{index, index + len(raw)}, true
+		}
+		return redactSpan{}, false
+	}
+	if res.Indexes == nil {
+		if s, ok := at(res.Index, res.Raw); ok {
+			return []redactSpan{s}, true
+		}
+		return nil, false
+	}
+	values := res.Array()
+	spans := make([]redactSpan, 0, len(values))
+	for i, value := range values {
+		if i >= len(res.Indexes) {
+			return spans, false
+		}
+		if s, ok := at(res.Indexes[i], value.Raw); ok {
+			spans = append(spans, s)
+		}
+	}
+	return spans, len(spans) == len(values)
+}
+
+// splitProjection splits path around its first '#' component that is
+// followed by more of the path, such as "a" and "b.c" for "a.#.b.c"
+func splitProjection(path string) (prefix, rest string, ok bool) {
+	depth := 0
+	start := 0 // the start of the current component
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			i++
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth > 0 {
+				depth--
+			}
+		case '|':
+			if depth == 0 {
+				return "", "", false
+			}
+		case '.':
+			if depth > 0 {
+				continue
+			}
+			if path[start:i] == "#" {
+				if start == 0 {
+					return "", path[i+1:], true
+				}
+				return path[:start-1], path[i+1:], true
+			}
+			start = i + 1
+		}
+	}
+	return "", "", false
+}
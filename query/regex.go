@@ -0,0 +1,87 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// maxCachedRegexps limits the number of compiled patterns that are kept.
+// The cache is cleared when it is full, so paths built from untrusted input
+// cannot grow it without bound.
+const maxCachedRegexps = 1024
+
+// regexCache holds the compiled RE2 patterns of the '~=' filter operators,
+// keyed by the operator flags and the pattern.
+var regexCache struct {
+	sync.RWMutex
+	m map[string]regexEntry
+}
+
+type regexEntry struct {
+	re  *regexp.Regexp
+	err error
+}
+
+// isRegexOp returns true if the query value starts with one of the regular
+// expression operators '~=', '~*=', '!~=' or '!~*='.
+func isRegexOp(value string) bool {
+	return strings.HasPrefix(value, "~=") || strings.HasPrefix(value, "~*=") ||
+		strings.HasPrefix(value, "!~=") || strings.HasPrefix(value, "!~*=")
+}
+
+// compileRegex returns the compiled pattern for a regular expression
+// operator. The '~*=' and '!~*=' operators are case-insensitive.
+func compileRegex(op, pattern string) (*regexp.Regexp, error) {
+	key := pattern
+	if strings.Contains(op, "*") {
+		key = "(?i)" + pattern
+	}
+	regexCache.RLock()
+	ent, ok := regexCache.m[key]
+	regexCache.RUnlock()
+	if ok {
+		return ent.re, ent.err
+	}
+	ent.re, ent.err = regexp.Compile(key)
+	if ent.err != nil {
+		ent.err = fmt.Errorf("%w: regexp %q: %v", ErrInvalidPath, pattern,
+			ent.err)
+	}
+	regexCache.Lock()
+	if regexCache.m == nil || len(regexCache.m) >= maxCachedRegexps {
+		regexCache.m = make(map[string]regexEntry)
+	}
+	regexCache.m[key] = ent
+	regexCache.Unlock()
+	return ent.re, ent.err
+}
+
+// matchRegex matches the string against the pattern of a regular expression
+// operator. Invalid patterns, and engines with DisableRegex, match nothing.
+func matchRegex(e *Engine, op, str, pattern string) bool {
+	if e.regexDisabled() {
+		return false
+	}
+	re, err := compileRegex(op, pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(str) != (op[0] == '!')
+}
+
+// checkRegex reports the errors of a regular expression operator when a
+// path is compiled.
+func checkRegex(e *Engine, op, pattern string) error {
+	switch op {
+	case "~=", "~*=", "!~=", "!~*=":
+	default:
+		return nil
+	}
+	if e.regexDisabled() {
+		return fmt.Errorf("%w: regular expressions are disabled", ErrInvalidPath)
+	}
+	_, err := compileRegex(op, pattern)
+	return err
+}
@@ -1,11 +1,20 @@
 package query
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 )
 
+var (
+	ErrNotExist = errors.New("query: value does not exist") // 值不存在
+	ErrType     = errors.New("query: wrong value type")     // 值的类型不匹配
+	ErrRange    = errors.New("query: value out of range")   // 值超出目标类型的范围
+	ErrLossy    = errors.New("query: lossy conversion")     // 转换会丢失精度
+)
+
 // Result 表示从Get()返回的json值。
 type Result struct {
 	Type    Type    // json类型
@@ -201,3 +210,192 @@ func (t Result) IsArray() bool {
 func (t Result) Exists() bool {
 	return t.Type != Null || len(t.Raw) != 0
 }
+
+// IntE 返回int64表示，类型不匹配、超出范围或有损转换时返回错误。
+// 与Int不同，布尔值不会被转换为0或1，小数不会被截断。
+func (t Result) IntE() (int64, error) {
+	switch t.Type {
+	case Number:
+		return numberToInt(t.Raw, t.Num)
+	case String:
+		if _, ok := parseDecimal(t.Str); !ok {
+			return 0, t.typeError("int")
+		}
+		return numberToInt(t.Str, 0)
+	}
+	return 0, t.typeError("int")
+}
+
+// UintE 返回uint64表示，类型不匹配、负数、超出范围或有损转换时返回错误。
+func (t Result) UintE() (uint64, error) {
+	switch t.Type {
+	case Number:
+		return numberToUint(t.Raw, t.Num)
+	case String:
+		if _, ok := parseDecimal(t.Str); !ok {
+			return 0, t.typeError("uint")
+		}
+		return numberToUint(t.Str, 0)
+	}
+	return 0, t.typeError("uint")
+}
+
+// FloatE 返回float64表示，类型不匹配或超出float64范围时返回错误。
+func (t Result) FloatE() (float64, error) {
+	var raw string
+	switch t.Type {
+	case Number:
+		if t.Raw == "" {
+			return t.Num, nil
+		}
+		raw = t.Raw
+	case String:
+		raw = t.Str
+	default:
+		return 0, t.typeError("float")
+	}
+	n, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, fmt.Errorf("%w: %s overflows float64", ErrRange, raw)
+		}
+		return 0, t.typeError("float")
+	}
+	return n, nil
+}
+
+// BoolE 返回布尔表示，只接受true、false以及可被strconv.ParseBool解析的字符串。
+func (t Result) BoolE() (bool, error) {
+	switch t.Type {
+	case True:
+		return true, nil
+	case False:
+		return false, nil
+	case String:
+		b, err := strconv.ParseBool(strings.ToLower(t.Str))
+		if err == nil {
+			return b, nil
+		}
+	}
+	return false, t.typeError("bool")
+}
+
+// TimeE 返回 time.Time 表示，值必须是RFC3339格式的字符串。
+func (t Result) TimeE() (time.Time, error) {
+	if t.Type != String {
+		return time.Time{}, t.typeError("time")
+	}
+	res, err := time.Parse(time.RFC3339, t.Str)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", ErrType, err)
+	}
+	return res, nil
+}
+
+// MustInt 与IntE相同，但出错时会panic，适合在测试中使用。
+func (t Result) MustInt() int64 {
+	n, err := t.IntE()
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// MustUint 与UintE相同，但出错时会panic。
+func (t Result) MustUint() uint64 {
+	n, err := t.UintE()
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// MustFloat 与FloatE相同，但出错时会panic。
+func (t Result) MustFloat() float64 {
+	n, err := t.FloatE()
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// MustBool 与BoolE相同，但出错时会panic。
+func (t Result) MustBool() bool {
+	b, err := t.BoolE()
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// MustTime 与TimeE相同，但出错时会panic。
+func (t Result) MustTime() time.Time {
+	tm, err := t.TimeE()
+	if err != nil {
+		panic(err)
+	}
+	return tm
+}
+
+// typeError returns ErrNotExist for missing values, otherwise an ErrType
+// describing the json type that could not be converted.
+func (t Result) typeError(want string) error {
+	if !t.Exists() {
+		return ErrNotExist
+	}
+	return fmt.Errorf("%w: cannot convert %s to %s", ErrType, t.Type, want)
+}
+
+// numberToInt converts a json number to an int64 without losing precision.
+// The text is parsed as an exact decimal, so a fractional part that float64
+// would round away, such as in 1.0000000000000001, is still rejected.
+func numberToInt(raw string, num float64) (int64, error) {
+	d, err := exactInt(raw, num, "int64")
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseInt(d.String(), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s overflows int64", ErrRange, d)
+	}
+	return i, nil
+}
+
+// numberToUint is like numberToInt but rejects negative values.
+func numberToUint(raw string, num float64) (uint64, error) {
+	d, err := exactInt(raw, num, "uint64")
+	if err != nil {
+		return 0, err
+	}
+	if d.neg && d.digits != "" {
+		return 0, fmt.Errorf("%w: %s is negative", ErrRange, d)
+	}
+	u, err := strconv.ParseUint(d.digits+strings.Repeat("0", d.exp), 10, 64)
+	if d.digits == "" {
+		u, err = 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s overflows uint64", ErrRange, d)
+	}
+	return u, nil
+}
+
+// exactInt parses the text of a number as a decimal that must be an
+// integer, num is formatted when there is no text.
+func exactInt(raw string, num float64, typ string) (decimal, error) {
+	if raw == "" {
+		raw = strconv.FormatFloat(num, 'f', -1, 64)
+	}
+	d, ok := parseDecimal(raw)
+	if !ok {
+		// NaN and the infinities
+		return decimal{}, fmt.Errorf("%w: %s overflows %s", ErrRange, raw, typ)
+	}
+	if !d.isInt() {
+		return decimal{}, fmt.Errorf("%w: %s is not an integer", ErrLossy, raw)
+	}
+	if d.size() > 20 {
+		return decimal{}, fmt.Errorf("%w: %s overflows %s", ErrRange, raw, typ)
+	}
+	return d, nil
+}
@@ -0,0 +1,246 @@
+package query
+
+import "strconv"
+
+// arraySlice is a negative index such as '-1', or a Python style slice such
+// as '[1:4]', '[-2:]' or '[::2]', in an array path component:
+//
+//	friends.-1.first        -> the first name of the last friend
+//	friends.[0:2].first     -> ["Dale","Roger"]
+//	friends.#.nets.[-1:]    -> the last net of each friend
+//
+// Slices return an array of the selected elements with Indexes populated,
+// like a '#' projection. A slice at the end of a path also has the Index of
+// the array it selects from, so a '#' projection of slices has the offsets
+// of the sliced arrays. A '#' component after a slice applies to the sliced
+// array, as in friends.[1:].# or friends.[1:].#.first.
+type arraySlice struct {
+	index  bool // a single negative index, stored in vals[0]
+	vals   [3]int
+	bounds [3]bool // whether the start, end and step are set
+}
+
+// parseSlice parses a negative index or a slice path component.
+func parseSlice(part string) (s arraySlice, ok bool) {
+	if len(part) > 1 && part[0] == '-' {
+		n, ok := parseUint(part[1:])
+		if !ok || n == 0 || n > maxJSONPathInt || part[1] == '+' {
+			return s, false
+		}
+		s.index, s.vals[0] = true, -int(n)
+		return s, true
+	}
+	if len(part) < 3 || part[0] != '[' || part[len(part)-1] != ']' {
+		return s, false
+	}
+	var k, start int
+	body := part[1 : len(part)-1]
+	for i := 0; i <= len(body); i++ {
+		if i < len(body) && body[i] != ':' {
+			continue
+		}
+		if k == 3 {
+			return s, false
+		}
+		if num := body[start:i]; num != "" {
+			n, err := strconv.Atoi(num)
+			if err != nil || n > maxJSONPathInt || n < -maxJSONPathInt {
+				return s, false
+			}
+			s.vals[k], s.bounds[k] = n, true
+		}
+		k++
+		start = i + 1
+	}
+	if k < 2 {
+		// '[1]' without a colon is not a slice
+		return s, false
+	}
+	return s, true
+}
+
+// isSliceComp returns true if the path starts with a slice component, which
+// must not be taken for a '[path1,path2]' selector.
+func isSliceComp(path string) bool {
+	for i := 0; i < len(path); i++ {
+		if path[i] == ']' {
+			if i+1 < len(path) && path[i+1] != '.' && path[i+1] != '|' {
+				return false
+			}
+			_, ok := parseSlice(path[:i+1])
+			return ok
+		}
+	}
+	return false
+}
+
+// sliceIndexes returns the indexes that the slice selects from an array
+// with n elements. The bounds are clamped like in Python and RFC 9535.
+func sliceIndexes(n int, vals [3]int, bounds [3]bool) []int {
+	step := 1
+	if bounds[2] {
+		step = vals[2]
+	}
+	if step == 0 {
+		return nil
+	}
+	var start, end int
+	if step > 0 {
+		start, end = 0, n
+	} else {
+		start, end = n-1, -n-1
+	}
+	if bounds[0] {
+		start = vals[0]
+	}
+	if bounds[1] {
+		end = vals[1]
+	}
+	if start < 0 {
+		start += n
+	}
+	if end < 0 {
+		end += n
+	}
+	var idxs []int
+	if step > 0 {
+		lower, upper := clampInt(start, 0, n), clampInt(end, 0, n)
+		for i := lower; i < upper; i += step {
+			idxs = append(idxs, i)
+		}
+	} else {
+		upper, lower := clampInt(start, -1, n-1), clampInt(end, -1, n-1)
+		for i := upper; i > lower; i += step {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
+
+func clampInt(i, min, max int) int {
+	if i < min {
+		return min
+	}
+	if i > max {
+		return max
+	}
+	return i
+}
+
+// parseArraySlice reads the array that starts at i, then selects the
+// elements of the negative index or slice. The rest of the path is applied
+// to each selected element, or to the sliced array when it starts with '#'.
+func parseArraySlice(c *parseContext, i int, rp *arrayPathResult,
+	s arraySlice) (int, bool) {
+	arrayStart := i - 1
+	var elems []Result
+	for i < len(c.json) {
+		ch := c.json[i]
+		if ch <= ' ' || ch == ',' {
+			i++
+			continue
+		}
+		if ch == ']' {
+			i++
+			break
+		}
+		start := i
+		var elem Result
+		var ok bool
+		if i, elem, ok = parseAny(c.json, i, true); !ok {
+			return i, false
+		}
+		elem.Index = start
+		elems = append(elems, elem)
+	}
+	if s.index {
+		n := len(elems) + s.vals[0]
+		if n < 0 {
+			return i, false
+		}
+		c.value = elems[n]
+		if rp.more {
+			c.value = elems[n].getPath(c.e, rp.path, nil)
+		}
+		return i, c.value.Exists()
+	}
+	if rp.more && rp.path[0] == '#' {
+		return i, sliceProjection(c, elems,
+			sliceIndexes(len(elems), s.vals, s.bounds), rp.path)
+	}
+	path := rp.path
+	if rp.more {
+		if left, right, ok := splitPossiblePipe(path); ok {
+			path = left
+			c.pipe = right
+			c.pipePath = nil
+			c.piped = true
+		}
+	}
+	indexes := []int{}
+	raw := []byte{'['}
+	for _, k := range sliceIndexes(len(elems), s.vals, s.bounds) {
+		res := elems[k]
+		if rp.more {
+			res = res.getPath(c.e, path, nil)
+		}
+		val := res.Raw
+		if val == "" {
+			val = res.String()
+		}
+		if val == "" {
+			continue
+		}
+		if len(raw) > 1 {
+			raw = append(raw, ',')
+		}
+		raw = append(raw, val...)
+		indexes = append(indexes, res.Index)
+	}
+	c.value = Result{
+		Type:    JSON,
+		Raw:     string(append(raw, ']')),
+		Indexes: indexes,
+	}
+	if !rp.more {
+		c.value.Index = arrayStart
+	}
+	c.calcd = true
+	return i, true
+}
+
+// sliceProjection applies the path to the array of the selected elements,
+// the offsets of the result are moved from that array to the json.
+func sliceProjection(c *parseContext, elems []Result, idxs []int,
+	path string) bool {
+	raw := []byte{'['}
+	starts := make([]int, len(idxs))
+	for n, k := range idxs {
+		if n > 0 {
+			raw = append(raw, ',')
+		}
+		starts[n] = len(raw)
+		raw = append(raw, elems[k].Raw...)
+	}
+	raw = append(raw, ']')
+	offset := func(x int) int {
+		for n, k := range idxs {
+			if x >= starts[n] && x < starts[n]+len(elems[k].Raw) {
+				return elems[k].Index + x - starts[n]
+			}
+		}
+		return 0
+	}
+	res := getPath(c.e, string(raw), path, nil)
+	if res.Indexes != nil {
+		for n := range res.Indexes {
+			res.Indexes[n] = offset(res.Indexes[n])
+		}
+		res.Index = 0
+	} else {
+		res.Index = offset(res.Index)
+	}
+	c.value = res
+	c.calcd = true
+	return res.Exists()
+}
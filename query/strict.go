@@ -0,0 +1,151 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidJSON json的语法无效
+	ErrInvalidJSON = errors.New("query: invalid json")
+	// ErrDuplicateKey 严格校验时，同一个对象中有重复的键
+	ErrDuplicateKey = errors.New("query: duplicate key")
+	// ErrInvalidUTF8 严格校验时，字符串不是有效的UTF-8
+	ErrInvalidUTF8 = errors.New("query: invalid UTF-8")
+	// ErrLoneSurrogate 严格校验时，字符串中有未配对的 \uD800-\uDFFF 转义
+	ErrLoneSurrogate = errors.New("query: lone surrogate")
+	// ErrNumberRange 严格校验时，数字超出了IEEE 754双精度浮点数的范围
+	ErrNumberRange = errors.New("query: number out of range")
+)
+
+// ValidStrict 按RFC 8259和I-JSON (RFC 7493) 严格校验json。除了 Valid 的语法
+// 检查之外，字符串必须是有效的UTF-8且没有未配对的代理项转义，数字不能超出
+// 双精度浮点数的范围，同一个对象中的键不能重复。
+// 有效时返回nil，否则返回的错误包装了 ErrInvalidJSON、ErrInvalidUTF8、
+// ErrLoneSurrogate、ErrNumberRange 或 ErrDuplicateKey，并包含字节偏移量，
+// 重复的键还包含其路径，例如 "a.1.b"。
+func ValidStrict(json string) error {
+	if i, ok := validpayload(stringBytes(json), 0); !ok {
+		return fmt.Errorf("%w at offset %d", ErrInvalidJSON, i)
+	}
+	if err := checkIJSON(json); err != nil {
+		return err
+	}
+	root := Parse(json)
+	root.Index = len(json) - len(strings.TrimLeft(json, " \t\r\n"))
+	return checkDuplicateKeys(root, nil)
+}
+
+// ValidStrictBytes 与ValidStrict相同，但使用字节切片作为输入。
+func ValidStrictBytes(json []byte) error {
+	return ValidStrict(bytesString(json))
+}
+
+// checkDuplicateKeys walks the valid json and returns an error for the
+// first key that is repeated in its object.
+func checkDuplicateKeys(res Result, path []string) (err error) {
+	if res.Type != JSON {
+		return nil
+	}
+	object := res.IsObject()
+	var seen map[string]bool
+	if object {
+		seen = make(map[string]bool)
+	}
+	i := 0
+	res.ForEach(func(key, value Result) bool {
+		comp := strconv.Itoa(i)
+		if object {
+			comp = escapeComp(key.Str)
+		}
+		i++
+		p := append(path[:len(path):len(path)], comp)
+		if object && seen[key.Str] {
+			err = fmt.Errorf("%w: %s at offset %d", ErrDuplicateKey,
+				strings.Join(p, "."), key.Index)
+			return false
+		}
+		if object {
+			seen[key.Str] = true
+		}
+		err = checkDuplicateKeys(value, p)
+		return err == nil
+	})
+	return err
+}
+
+// checkIJSON checks the strings and numbers of the valid json.
+func checkIJSON(json string) error {
+	for i := 0; i < len(json); i++ {
+		switch c := json[i]; {
+		case c == '"':
+			end, err := checkIJSONString(json, i+1)
+			if err != nil {
+				return err
+			}
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			start := i
+			for ; i+1 < len(json); i++ {
+				c := json[i+1]
+				if (c < '0' || c > '9') && c != '.' && c != 'e' && c != 'E' &&
+					c != '+' && c != '-' {
+					break
+				}
+			}
+			f, _ := strconv.ParseFloat(json[start:i+1], 64)
+			if math.IsInf(f, 0) {
+				return fmt.Errorf("%w at offset %d", ErrNumberRange, start)
+			}
+		}
+	}
+	return nil
+}
+
+// checkIJSONString checks the string that starts at i, after the quote, and
+// returns the index of the closing quote.
+func checkIJSONString(json string, i int) (int, error) {
+	for ; i < len(json); i++ {
+		c := json[i]
+		switch {
+		case c == '"':
+			return i, nil
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRuneInString(json[i:])
+			if r == utf8.RuneError && size == 1 {
+				return i, fmt.Errorf("%w at offset %d", ErrInvalidUTF8, i)
+			}
+			i += size - 1
+		case c == '\\':
+			i++
+			if json[i] != 'u' {
+				continue
+			}
+			r := hexRune(json[i+1 : i+5])
+			if !utf16.IsSurrogate(r) {
+				i += 4
+				continue
+			}
+			if r < 0xDC00 && i+10 < len(json) && json[i+5] == '\\' &&
+				json[i+6] == 'u' {
+				r2 := hexRune(json[i+7 : i+11])
+				if r2 >= 0xDC00 && r2 <= 0xDFFF {
+					i += 10
+					continue
+				}
+			}
+			return i, fmt.Errorf("%w at offset %d", ErrLoneSurrogate, i-1)
+		}
+	}
+	return i, nil
+}
+
+func hexRune(hex string) rune {
+	n, _ := strconv.ParseUint(hex, 16, 32)
+	return rune(n)
+}